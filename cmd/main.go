@@ -20,6 +20,10 @@ func main() {
 		log.Fatal("Error loading config")
 	}
 
+	if err := shared.LoadMediaConfig().Validate(); err != nil {
+		log.Fatalf("Error loading media config: %v", err)
+	}
+
 	db, err := database.GetDB(env)
 	if err != nil {
 		return
@@ -0,0 +1,66 @@
+-- Product search: full text over title, description and category names plus
+-- typo tolerant title matching. Every statement is idempotent, run it once per
+-- database with a role that is allowed to create extensions:
+--
+--   psql "$DB_URL" -f internal/database/sql/product_search.sql
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- `keyword <% title` is what the search query uses so the trigram index below
+-- can serve it, matches start at a word similarity of 0.4
+DO $$
+BEGIN
+	EXECUTE format('ALTER DATABASE %I SET pg_trgm.word_similarity_threshold = 0.4', current_database());
+END
+$$;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_document tsvector;
+
+-- category names live in other tables so search_document can not be a
+-- generated column, it is kept up to date by triggers instead
+CREATE OR REPLACE FUNCTION products_search_document() RETURNS trigger AS $$
+BEGIN
+	NEW.search_document :=
+		setweight(to_tsvector('simple', NEW.title), 'A') ||
+		setweight(to_tsvector('simple', COALESCE(NEW.description, '')), 'B') ||
+		setweight(to_tsvector('simple',
+			COALESCE((SELECT c1.name FROM categories_lv1 c1 WHERE c1.id = NEW.category_lv1_id), '') || ' ' ||
+			COALESCE((SELECT c2.name FROM categories_lv2 c2 WHERE c2.id = NEW.category_lv2_id), '') || ' ' ||
+			COALESCE((SELECT c3.name FROM categories_lv3 c3 WHERE c3.id = NEW.category_lv3_id), '')), 'C');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_search_document ON products;
+CREATE TRIGGER products_search_document
+	BEFORE INSERT OR UPDATE OF title, description, category_lv1_id, category_lv2_id, category_lv3_id ON products
+	FOR EACH ROW EXECUTE FUNCTION products_search_document();
+
+-- renaming a category rebuilds the document of every product filed under it
+CREATE OR REPLACE FUNCTION category_search_document() RETURNS trigger AS $$
+BEGIN
+	EXECUTE format('UPDATE products SET title = title WHERE %I = $1', TG_ARGV[0]) USING NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS categories_lv1_search_document ON categories_lv1;
+CREATE TRIGGER categories_lv1_search_document
+	AFTER UPDATE OF name ON categories_lv1
+	FOR EACH ROW EXECUTE FUNCTION category_search_document('category_lv1_id');
+
+DROP TRIGGER IF EXISTS categories_lv2_search_document ON categories_lv2;
+CREATE TRIGGER categories_lv2_search_document
+	AFTER UPDATE OF name ON categories_lv2
+	FOR EACH ROW EXECUTE FUNCTION category_search_document('category_lv2_id');
+
+DROP TRIGGER IF EXISTS categories_lv3_search_document ON categories_lv3;
+CREATE TRIGGER categories_lv3_search_document
+	AFTER UPDATE OF name ON categories_lv3
+	FOR EACH ROW EXECUTE FUNCTION category_search_document('category_lv3_id');
+
+-- backfill, the update fires the products trigger
+UPDATE products SET title = title WHERE search_document IS NULL;
+
+CREATE INDEX IF NOT EXISTS products_search_document_idx ON products USING GIN (search_document);
+CREATE INDEX IF NOT EXISTS products_title_trgm_idx ON products USING GIN (title gin_trgm_ops);
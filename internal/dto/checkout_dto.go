@@ -28,8 +28,12 @@ type OrderDetails struct {
 	CourierPrice        decimal.Decimal `json:"courier_price"`
 	FinalPrice          decimal.Decimal `json:"final_price"`
 	InitialPrice        decimal.Decimal `json:"initial_price"`
+	PlatformDiscount    decimal.Decimal `json:"platform_discount"`
+	ShippingDiscount    decimal.Decimal `json:"shipping_discount"`
 	Invoice             string          `json:"invoice"`
 	OrderDetailProducts []OrderDetailProducts
+
+	ShippingVoucherScope *string
 }
 
 type OrderDetailProducts struct {
@@ -44,4 +48,7 @@ type OrderDetailProducts struct {
 	FinalPrice                  decimal.Decimal `json:"final_price"`
 	InitialPrice                decimal.Decimal `json:"initial_price"`
 	MerchantId                  uint64          `json:"merchant_id"`
+	FlashSaleProductId          *uint64         `json:"flash_sale_product_id,omitempty"`
+	BundlePromotionId           *uint64         `json:"bundle_promotion_id,omitempty"`
+	BundleSaving                decimal.Decimal `json:"bundle_saving"`
 }
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"digital-test-vm/be/internal/model"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type FlashSaleProduct struct {
+	ProductID                   uint64
+	VariantCombinationProductID uint64
+	SalePrice                   decimal.Decimal
+	SaleStock                   uint64
+	PurchaseLimit               uint64
+}
+
+type ManageFlashSale struct {
+	ID        uint64
+	Name      string
+	StartDate time.Time
+	EndDate   time.Time
+	Products  []FlashSaleProduct
+}
+
+func RequestToManageFlashSaleDTO(req ManageFlashSaleRequest) (*ManageFlashSale, error) {
+	dateString := "2006-01-02 15:04"
+	startDate, err := time.ParseInLocation(dateString, req.StartDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("RequestToManageFlashSaleDTO: %w", err)
+	}
+
+	endDate, err := time.ParseInLocation(dateString, req.EndDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("RequestToManageFlashSaleDTO: %w", err)
+	}
+
+	flashSale := &ManageFlashSale{
+		Name:      req.Name,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	for _, p := range req.Products {
+		salePrice, err := decimal.NewFromString(p.SalePrice)
+		if err != nil {
+			return nil, fmt.Errorf("RequestToManageFlashSaleDTO: %w", err)
+		}
+		flashSale.Products = append(flashSale.Products, FlashSaleProduct{
+			VariantCombinationProductID: p.VariantCombinationProductID,
+			SalePrice:                   salePrice,
+			SaleStock:                   p.SaleStock,
+			PurchaseLimit:               p.PurchaseLimit,
+		})
+	}
+	return flashSale, nil
+}
+
+func (f *ManageFlashSale) ToModel(merchantID uint64) *model.FlashSale {
+	flashSale := &model.FlashSale{
+		ID:         f.ID,
+		MerchantID: merchantID,
+		Name:       f.Name,
+		StartDate:  f.StartDate,
+		EndDate:    f.EndDate,
+	}
+	for _, p := range f.Products {
+		flashSale.Products = append(flashSale.Products, model.FlashSaleProduct{
+			ProductID:                   p.ProductID,
+			VariantCombinationProductID: p.VariantCombinationProductID,
+			SalePrice:                   p.SalePrice,
+			SaleStock:                   p.SaleStock,
+			PurchaseLimit:               p.PurchaseLimit,
+		})
+	}
+	return flashSale
+}
@@ -18,6 +18,8 @@ type Merchant struct {
 	Address     *Address
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	RatingBreakdown *model.MerchantRatingBreakdown
 }
 
 func MerchantToDTO(merchant model.Merchant) *Merchant {
@@ -49,5 +51,7 @@ func (m *Merchant) ToResponse() *MerchantResponse {
 		Address:     m.Address,
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
+
+		RatingBreakdown: m.RatingBreakdown,
 	}
 }
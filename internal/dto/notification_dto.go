@@ -0,0 +1,8 @@
+package dto
+
+import "digital-test-vm/be/internal/model"
+
+type NotificationListResponse struct {
+	Notifications []model.Notification `json:"notifications"`
+	UnreadCount   int64                `json:"unread_count"`
+}
@@ -0,0 +1,302 @@
+package dto
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const ProductImportMaxRows = 2000
+
+var (
+	ErrProductCSVHeader  = errors.New("csv header does not match the product template")
+	ErrProductCSVTooLong = fmt.Errorf("csv has more than %d rows", ProductImportMaxRows)
+)
+
+/* one row per variant combination, the product columns are read from the first row of each product */
+var ProductCSVHeader = []string{
+	"product_id", "title", "description", "category_lv1_id", "category_lv2_id", "category_lv3_id",
+	"length", "width", "height", "weight", "is_used", "is_hazardous", "photos", "video",
+	"parent_group", "parent_type", "child_group", "child_type", "price", "stock",
+}
+
+const productCSVPhotoSeparator = "|"
+
+/* a product assembled from its csv rows, ID is zero for products that do not exist yet */
+type ProductImport struct {
+	Row     int
+	Product ManageProduct
+}
+
+type ProductImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ProductImportBatch struct {
+	Products []ProductImport
+	Errors   []ProductImportRowError
+}
+
+type ProductImportReport struct {
+	Created uint64                  `json:"created"`
+	Updated uint64                  `json:"updated"`
+	Failed  uint64                  `json:"failed"`
+	Errors  []ProductImportRowError `json:"errors"`
+}
+
+/*
+groups the rows of the csv into products, rows with the same product_id (or the same title when there is
+no product_id) belong to the same product. a product with an invalid row is left out of the batch
+*/
+func ParseProductCSV(r io.Reader) (*ProductImportBatch, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(ProductCSVHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrProductCSVHeader
+	}
+	for i, h := range header {
+		if strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))) != ProductCSVHeader[i] {
+			return nil, ErrProductCSVHeader
+		}
+	}
+
+	batch := &ProductImportBatch{Products: []ProductImport{}, Errors: []ProductImportRowError{}}
+	products := map[string]*ProductImport{}
+	failed := map[string]bool{}
+	keys := []string{}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if row > ProductImportMaxRows+1 {
+			return nil, ErrProductCSVTooLong
+		}
+		if err != nil {
+			batch.Errors = append(batch.Errors, ProductImportRowError{Row: row, Message: err.Error()})
+			continue
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		key := "title:" + strings.ToLower(record[1])
+		if record[0] != "" {
+			key = "id:" + record[0]
+		}
+
+		p, exist := products[key]
+		if !exist && failed[key] {
+			continue
+		}
+		if !exist {
+			product, err := productFromCSVRecord(record)
+			if err != nil {
+				batch.Errors = append(batch.Errors, ProductImportRowError{Row: row, Message: err.Error()})
+				failed[key] = true
+				continue
+			}
+			p = &ProductImport{Row: row, Product: *product}
+			products[key] = p
+			keys = append(keys, key)
+		}
+
+		if err := p.addCSVCombination(record); err != nil {
+			batch.Errors = append(batch.Errors, ProductImportRowError{Row: row, Message: err.Error()})
+			failed[key] = true
+		}
+	}
+
+	for _, key := range keys {
+		if !failed[key] {
+			batch.Products = append(batch.Products, *products[key])
+		}
+	}
+	return batch, nil
+}
+
+func productFromCSVRecord(record []string) (*ManageProduct, error) {
+	product := &ManageProduct{
+		Title:         record[1],
+		Description:   record[2],
+		CategoryLV1ID: record[3],
+		CategoryLV2ID: record[4],
+		CategoryLV3ID: record[5],
+		Video:         record[13],
+		Photos:        []ManageProductPhoto{},
+	}
+
+	if record[0] != "" {
+		id, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product_id %q", record[0])
+		}
+		product.ID = id
+	}
+	if product.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if product.Description == "" {
+		return nil, errors.New("description is required")
+	}
+	if product.CategoryLV1ID == "" {
+		return nil, errors.New("category_lv1_id is required")
+	}
+
+	dimensions := []*decimal.Decimal{&product.Length, &product.Width, &product.Height, &product.Weight}
+	for i, d := range dimensions {
+		value, err := decimal.NewFromString(record[6+i])
+		if err != nil || value.IsNegative() {
+			return nil, fmt.Errorf("invalid %s %q", ProductCSVHeader[6+i], record[6+i])
+		}
+		*d = value
+	}
+
+	var err error
+	if product.IsUsed, err = parseCSVBool(record[10]); err != nil {
+		return nil, fmt.Errorf("invalid is_used %q", record[10])
+	}
+	if product.IsHazardous, err = parseCSVBool(record[11]); err != nil {
+		return nil, fmt.Errorf("invalid is_hazardous %q", record[11])
+	}
+
+	if record[12] != "" {
+		for i, url := range strings.Split(record[12], productCSVPhotoSeparator) {
+			product.Photos = append(product.Photos, ManageProductPhoto{URL: strings.TrimSpace(url), IsDefault: i == 0})
+		}
+	}
+
+	product.Variants = ManageVariant{
+		Parent:       ManageVariantGroup{Group: record[14], Types: []ManageVariantType{}},
+		Combinations: []ManageVariantCombination{},
+	}
+	if record[16] != "" {
+		product.Variants.Child = &ManageVariantGroup{Group: record[16], Types: []ManageVariantType{}}
+	}
+	return product, nil
+}
+
+/* every row adds one combination, the variant groups must be the same on every row of a product */
+func (p *ProductImport) addCSVCombination(record []string) error {
+	variants := &p.Product.Variants
+	if record[14] == "" || record[15] == "" {
+		return errors.New("parent_group and parent_type are required")
+	}
+	if !strings.EqualFold(record[14], variants.Parent.Group) {
+		return fmt.Errorf("parent_group %q differs from %q on row %d", record[14], variants.Parent.Group, p.Row)
+	}
+
+	childGroup := ""
+	if variants.Child != nil {
+		childGroup = variants.Child.Group
+	}
+	if !strings.EqualFold(record[16], childGroup) {
+		return fmt.Errorf("child_group %q differs from %q on row %d", record[16], childGroup, p.Row)
+	}
+	if (record[16] == "") != (record[17] == "") {
+		return errors.New("child_group and child_type must be filled together")
+	}
+
+	price, err := decimal.NewFromString(record[18])
+	if err != nil || !price.IsPositive() {
+		return fmt.Errorf("invalid price %q", record[18])
+	}
+	stock, err := strconv.ParseUint(record[19], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid stock %q", record[19])
+	}
+
+	combination := ManageVariantCombination{
+		ParentType: ManageVariantType{Type: record[15]},
+		Price:      price,
+		Stock:      stock,
+	}
+	if variants.Child != nil {
+		combination.ChildType = &ManageVariantType{Type: record[17]}
+	}
+	for _, c := range variants.Combinations {
+		if c.SameTypes(&combination) {
+			return fmt.Errorf("variant %s is listed more than once", combination.name())
+		}
+	}
+
+	variants.Parent.Types = appendVariantType(variants.Parent.Types, record[15])
+	if variants.Child != nil {
+		variants.Child.Types = appendVariantType(variants.Child.Types, record[17])
+	}
+	variants.Combinations = append(variants.Combinations, combination)
+	return nil
+}
+
+func appendVariantType(types []ManageVariantType, name string) []ManageVariantType {
+	for _, t := range types {
+		if t.Type == name {
+			return types
+		}
+	}
+	return append(types, ManageVariantType{Type: name})
+}
+
+func parseCSVBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+/* SameTypes reports whether both combinations are made of the same parent and child type names */
+func (mvc *ManageVariantCombination) SameTypes(other *ManageVariantCombination) bool {
+	if mvc.ParentType.Type != other.ParentType.Type {
+		return false
+	}
+	if mvc.ChildType == nil || other.ChildType == nil {
+		return mvc.ChildType == nil && other.ChildType == nil
+	}
+	return mvc.ChildType.Type == other.ChildType.Type
+}
+
+func (mvc *ManageVariantCombination) name() string {
+	if mvc.ChildType == nil {
+		return mvc.ParentType.Type
+	}
+	return mvc.ParentType.Type + " / " + mvc.ChildType.Type
+}
+
+/* one record per variant combination, the product columns are repeated so every row can be read on its own */
+func (mp *ManageProduct) ToCSVRecords() [][]string {
+	photos := []string{}
+	for _, pp := range mp.Photos {
+		if pp.IsDefault {
+			photos = append([]string{pp.URL}, photos...)
+		} else {
+			photos = append(photos, pp.URL)
+		}
+	}
+
+	records := [][]string{}
+	for _, c := range mp.Variants.Combinations {
+		childGroup, childType := "", ""
+		if mp.Variants.Child != nil && c.ChildType != nil {
+			childGroup, childType = mp.Variants.Child.Group, c.ChildType.Type
+		}
+		records = append(records, []string{
+			strconv.FormatUint(mp.ID, 10), mp.Title, mp.Description, mp.CategoryLV1ID, mp.CategoryLV2ID, mp.CategoryLV3ID,
+			mp.Length.String(), mp.Width.String(), mp.Height.String(), mp.Weight.String(),
+			strconv.FormatBool(mp.IsUsed), strconv.FormatBool(mp.IsHazardous),
+			strings.Join(photos, productCSVPhotoSeparator), mp.Video,
+			mp.Variants.Parent.Group, c.ParentType.Type, childGroup, childType,
+			c.Price.String(), strconv.FormatUint(c.Stock, 10),
+		})
+	}
+	return records
+}
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const productCSVTestHeader = "product_id,title,description,category_lv1_id,category_lv2_id,category_lv3_id," +
+	"length,width,height,weight,is_used,is_hazardous,photos,video,parent_group,parent_type,child_group,child_type,price,stock\n"
+
+func TestParseProductCSV(t *testing.T) {
+	tests := []struct {
+		name         string
+		csv          string
+		err          error
+		products     int
+		combinations []int
+		errorRows    []int
+	}{
+		{
+			name:         "rows of one product become its combinations",
+			csv:          productCSVTestHeader + ",Shirt,Cotton,1,,,1,1,1,200,false,false,a.jpg|b.jpg,,Color,Red,Size,S,100,5\n,shirt,Cotton,1,,,1,1,1,200,false,false,,,Color,Red,Size,M,110,3\n",
+			products:     1,
+			combinations: []int{2},
+		},
+		{
+			name:         "product_id groups rows before the title does",
+			csv:          productCSVTestHeader + "7,Cap,Wool,1,,,1,1,1,100,,,,,Color,Black,,,50,1\n,Cap,Wool,1,,,1,1,1,100,,,,,Color,Black,,,50,1\n",
+			products:     2,
+			combinations: []int{1, 1},
+		},
+		{
+			name:         "an invalid row drops the whole product",
+			csv:          productCSVTestHeader + ",Shirt,Cotton,1,,,1,1,1,200,,,,,Color,Red,,,100,5\n,Shirt,Cotton,1,,,1,1,1,200,,,,,Color,Blue,,,-1,5\n,Bag,Leather,1,,,1,1,1,500,,,,,Color,Brown,,,300,2\n",
+			products:     1,
+			combinations: []int{1},
+			errorRows:    []int{3},
+		},
+		{
+			name:      "missing title and bad numbers are reported per row",
+			csv:       productCSVTestHeader + ",,Cotton,1,,,1,1,1,200,,,,,Color,Red,,,100,5\n,Hat,Felt,1,,,x,1,1,200,,,,,Color,Red,,,100,5\n",
+			errorRows: []int{2, 3},
+		},
+		{
+			name:      "a combination listed twice is refused",
+			csv:       productCSVTestHeader + ",Mug,Ceramic,1,,,1,1,1,300,,,,,Color,White,,,40,5\n,Mug,Ceramic,1,,,1,1,1,300,,,,,Color,White,,,40,5\n",
+			errorRows: []int{3},
+		},
+		{
+			name:      "child group must match the first row",
+			csv:       productCSVTestHeader + ",Sock,Cotton,1,,,1,1,1,50,,,,,Color,Red,Size,S,10,5\n,Sock,Cotton,1,,,1,1,1,50,,,,,Color,Red,,,10,5\n",
+			errorRows: []int{3},
+		},
+		{
+			name: "header must match the template",
+			csv:  "title,description\nShirt,Cotton\n",
+			err:  ErrProductCSVHeader,
+		},
+		{
+			name: "empty file has no header",
+			csv:  "",
+			err:  ErrProductCSVHeader,
+		},
+		{
+			name: "too many rows",
+			csv:  productCSVTestHeader + strings.Repeat(",Shirt,Cotton,1,,,1,1,1,200,,,,,Color,Red,,,100,5\n", ProductImportMaxRows+1),
+			err:  ErrProductCSVTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := ParseProductCSV(strings.NewReader(tt.csv))
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err %v", err)
+			}
+			if len(batch.Products) != tt.products {
+				t.Fatalf("products = %d, want %d", len(batch.Products), tt.products)
+			}
+			for i, want := range tt.combinations {
+				if got := len(batch.Products[i].Product.Variants.Combinations); got != want {
+					t.Errorf("product %d combinations = %d, want %d", i, got, want)
+				}
+			}
+			if len(batch.Errors) != len(tt.errorRows) {
+				t.Fatalf("errors = %+v, want rows %v", batch.Errors, tt.errorRows)
+			}
+			for i, row := range tt.errorRows {
+				if batch.Errors[i].Row != row {
+					t.Errorf("error %d row = %d, want %d", i, batch.Errors[i].Row, row)
+				}
+			}
+		})
+	}
+}
+
+func TestParseProductCSVFields(t *testing.T) {
+	batch, err := ParseProductCSV(strings.NewReader(productCSVTestHeader +
+		"12,Shirt,Cotton,1,2,3,10,20,5,200.5,true,false,a.jpg|b.jpg,v.mp4,Color,Red,Size,S,100,5\n"))
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	if len(batch.Products) != 1 {
+		t.Fatalf("products = %d, want 1", len(batch.Products))
+	}
+
+	p := batch.Products[0].Product
+	if p.ID != 12 || p.Title != "Shirt" || p.CategoryLV3ID != "3" || !p.IsUsed || p.IsHazardous || p.Video != "v.mp4" {
+		t.Errorf("product = %+v", p)
+	}
+	if p.Weight.String() != "200.5" {
+		t.Errorf("weight = %s, want 200.5", p.Weight)
+	}
+	if len(p.Photos) != 2 || !p.Photos[0].IsDefault || p.Photos[1].IsDefault {
+		t.Errorf("photos = %+v", p.Photos)
+	}
+	if p.Variants.Child == nil || p.Variants.Child.Group != "Size" {
+		t.Fatalf("child group = %+v", p.Variants.Child)
+	}
+	c := p.Variants.Combinations[0]
+	if c.ParentType.Type != "Red" || c.ChildType == nil || c.ChildType.Type != "S" || c.Price.String() != "100" || c.Stock != 5 {
+		t.Errorf("combination = %+v", c)
+	}
+}
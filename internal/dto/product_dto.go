@@ -35,23 +35,24 @@ type Product struct {
 }
 
 type ListProduct struct {
-	ID            uint64
-	MerchantId    uint64
-	Username      string
-	Title         string
-	Photo         string
-	TotalSold     uint64
-	FavCount      uint64
-	AverageRating float64
-	TotalStock    uint64
-	City          string
-	CategoryLv1Id string
-	CategoryLv2Id string
-	CategoryLv3Id string
-	MinPrice      decimal.Decimal
-	IsActive      bool
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID             uint64
+	MerchantId     uint64
+	Username       string
+	Title          string
+	Photo          string
+	TotalSold      uint64
+	FavCount       uint64
+	AverageRating  float64
+	TotalStock     uint64
+	City           string
+	CategoryLv1Id  string
+	CategoryLv2Id  string
+	CategoryLv3Id  string
+	MinPrice       decimal.Decimal
+	FlashSalePrice *decimal.Decimal
+	IsActive       bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func ProductToDTO(product model.Product) *Product {
@@ -80,45 +81,47 @@ func ProductToDTO(product model.Product) *Product {
 
 func ListProductToDTO(product model.ListProduct) *ListProduct {
 	return &ListProduct{
-		ID:            product.ID,
-		MerchantId:    product.MerchantId,
-		Username:      product.Username,
-		Title:         product.Title,
-		Photo:         product.Photo,
-		TotalSold:     product.TotalSold,
-		FavCount:      product.FavCount,
-		TotalStock:    product.TotalStock,
-		City:          product.City,
-		AverageRating: product.AverageRating,
-		CategoryLv1Id: product.CategoryLv1Id,
-		CategoryLv2Id: product.CategoryLv2Id,
-		CategoryLv3Id: product.CategoryLv3Id,
-		MinPrice:      product.MinPrice,
-		IsActive:      product.IsActive,
-		CreatedAt:     product.CreatedAt,
-		UpdatedAt:     product.UpdatedAt,
+		ID:             product.ID,
+		MerchantId:     product.MerchantId,
+		Username:       product.Username,
+		Title:          product.Title,
+		Photo:          product.Photo,
+		TotalSold:      product.TotalSold,
+		FavCount:       product.FavCount,
+		TotalStock:     product.TotalStock,
+		City:           product.City,
+		AverageRating:  product.AverageRating,
+		CategoryLv1Id:  product.CategoryLv1Id,
+		CategoryLv2Id:  product.CategoryLv2Id,
+		CategoryLv3Id:  product.CategoryLv3Id,
+		MinPrice:       product.MinPrice,
+		FlashSalePrice: product.FlashSalePrice,
+		IsActive:       product.IsActive,
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
 	}
 }
 
 func (lp *ListProduct) ToResponse() *ListProductsResponse {
 	return &ListProductsResponse{
-		ID:            lp.ID,
-		MerchantId:    lp.MerchantId,
-		Username:      lp.Username,
-		Title:         lp.Title,
-		Photo:         lp.Photo,
-		TotalSold:     lp.TotalSold,
-		FavCount:      lp.FavCount,
-		AverageRating: lp.AverageRating,
-		TotalStock:    lp.TotalStock,
-		City:          lp.City,
-		CategoryLv1Id: lp.CategoryLv1Id,
-		CategoryLv2Id: lp.CategoryLv2Id,
-		CategoryLv3Id: lp.CategoryLv3Id,
-		MinPrice:      lp.MinPrice,
-		IsActive:      lp.IsActive,
-		CreatedAt:     lp.CreatedAt,
-		UpdatedAt:     lp.UpdatedAt,
+		ID:             lp.ID,
+		MerchantId:     lp.MerchantId,
+		Username:       lp.Username,
+		Title:          lp.Title,
+		Photo:          lp.Photo,
+		TotalSold:      lp.TotalSold,
+		FavCount:       lp.FavCount,
+		AverageRating:  lp.AverageRating,
+		TotalStock:     lp.TotalStock,
+		City:           lp.City,
+		CategoryLv1Id:  lp.CategoryLv1Id,
+		CategoryLv2Id:  lp.CategoryLv2Id,
+		CategoryLv3Id:  lp.CategoryLv3Id,
+		MinPrice:       lp.MinPrice,
+		FlashSalePrice: lp.FlashSalePrice,
+		IsActive:       lp.IsActive,
+		CreatedAt:      lp.CreatedAt,
+		UpdatedAt:      lp.UpdatedAt,
 	}
 }
 
@@ -138,6 +141,8 @@ type ManageProduct struct {
 	CategoryLV2ID string
 	CategoryLV3ID string
 	Variants      ManageVariant
+	IsDraft       bool
+	PublishAt     *time.Time
 }
 
 func ModelToManageProduct(product *model.Product) *ManageProduct {
@@ -155,6 +160,8 @@ func ModelToManageProduct(product *model.Product) *ManageProduct {
 		CategoryLV1ID: product.CategoryLv1Id,
 		CategoryLV2ID: product.CategoryLv2Id,
 		CategoryLV3ID: product.CategoryLv3Id,
+		IsDraft:       product.IsDraft,
+		PublishAt:     product.PublishAt,
 	}
 }
 
@@ -197,5 +204,92 @@ func (mp *ManageProduct) ToResponse() *ManageProductResponse {
 		CategoryLV2ID: mp.CategoryLV2ID,
 		CategoryLV3ID: mp.CategoryLV3ID,
 		Variants:      *mp.Variants.ToResponse(),
+		IsDraft:       mp.IsDraft,
+		PublishAt:     mp.PublishAt,
+	}
+}
+
+func ToProductSuggestionResponse(suggestion *model.ProductSuggestion) *ProductSuggestionResponse {
+	return &ProductSuggestionResponse{
+		Products:   suggestion.Products,
+		Categories: suggestion.Categories,
+		Merchants:  suggestion.Merchants,
+	}
+}
+
+func ToProductFacetsResponse(facets []model.ProductFacet) *ProductFacetsResponse {
+	res := &ProductFacetsResponse{
+		CategoryLv1: []ProductFacetBucketResponse{},
+		CategoryLv2: []ProductFacetBucketResponse{},
+		CategoryLv3: []ProductFacetBucketResponse{},
+		Locations:   []ProductFacetBucketResponse{},
+		Ratings:     []ProductFacetBucketResponse{},
+		Prices:      []ProductFacetBucketResponse{},
+	}
+	for _, f := range facets {
+		bucket := ProductFacetBucketResponse{
+			Value: f.Value,
+			Label: f.Label,
+			Count: f.Count,
+		}
+		if f.MinValue != nil {
+			min := f.MinValue.String()
+			bucket.Min = &min
+		}
+		if f.MaxValue != nil {
+			max := f.MaxValue.String()
+			bucket.Max = &max
+		}
+
+		switch f.Facet {
+		case "category_lv1":
+			res.CategoryLv1 = append(res.CategoryLv1, bucket)
+		case "category_lv2":
+			res.CategoryLv2 = append(res.CategoryLv2, bucket)
+		case "category_lv3":
+			res.CategoryLv3 = append(res.CategoryLv3, bucket)
+		case "location":
+			res.Locations = append(res.Locations, bucket)
+		case "rating":
+			res.Ratings = append(res.Ratings, bucket)
+		case "price":
+			if bucket.Min != nil && bucket.Max != nil {
+				bucket.Label = *bucket.Min + " - " + *bucket.Max
+			}
+			res.Prices = append(res.Prices, bucket)
+		}
+	}
+	return res
+}
+
+type DraftProductResponse struct {
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	IsDraft   bool       `json:"is_draft"`
+	PublishAt *time.Time `json:"publish_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func ToDraftProductResponse(product *model.Product) *DraftProductResponse {
+	return &DraftProductResponse{
+		ID:        product.ID,
+		Title:     product.Title,
+		IsDraft:   product.IsDraft,
+		PublishAt: product.PublishAt,
+		UpdatedAt: product.UpdatedAt,
+	}
+}
+
+type ArchivedProductResponse struct {
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func ToArchivedProductResponse(product *model.Product) *ArchivedProductResponse {
+	return &ArchivedProductResponse{
+		ID:        product.ID,
+		Title:     product.Title,
+		DeletedAt: product.DeletedAt,
 	}
 }
@@ -20,6 +20,8 @@ type Promotion struct {
 	Amount         decimal.Decimal
 	Quota          uint64
 	MaxAmount      *decimal.Decimal
+	BuyQuantity    uint64
+	GetQuantity    uint64
 	StartDate      time.Time
 	EndDate        time.Time
 	Products       []ListProduct
@@ -35,6 +37,12 @@ func PromotionToDTO(promotion model.Promotion) *Promotion {
 		promoType = shared.Discount
 	case promotion.PromotionType == shared.Cut.String():
 		promoType = shared.Cut
+	case promotion.PromotionType == shared.FreeShipping.String():
+		promoType = shared.FreeShipping
+	case promotion.PromotionType == shared.BuyXGetY.String():
+		promoType = shared.BuyXGetY
+	case promotion.PromotionType == shared.Bundle.String():
+		promoType = shared.Bundle
 	}
 
 	var promoScope shared.VoucherScope
@@ -55,6 +63,8 @@ func PromotionToDTO(promotion model.Promotion) *Promotion {
 		VoucherCode:    promotion.VoucherCode,
 		Amount:         decimal.NewFromFloat(promotion.Amount),
 		Quota:          promotion.Quota,
+		BuyQuantity:    promotion.BuyQuantity,
+		GetQuantity:    promotion.GetQuantity,
 		StartDate:      promotion.StartDate,
 		EndDate:        promotion.EndDate,
 		CreatedAt:      promotion.CreatedAt,
@@ -82,6 +92,8 @@ func (p *Promotion) ToModel() *model.Promotion {
 		VoucherCode:    p.VoucherCode,
 		Amount:         p.Amount.InexactFloat64(),
 		Quota:          p.Quota,
+		BuyQuantity:    p.BuyQuantity,
+		GetQuantity:    p.GetQuantity,
 		StartDate:      p.StartDate,
 		EndDate:        p.EndDate,
 	}
@@ -103,6 +115,12 @@ func RequestToManagePromotionDTO(req ManagePromotionRequest) (*ManagePromotion,
 		promoType = shared.Discount
 	case req.PromotionType == shared.Cut.String():
 		promoType = shared.Cut
+	case req.PromotionType == shared.FreeShipping.String():
+		promoType = shared.FreeShipping
+	case req.PromotionType == shared.BuyXGetY.String():
+		promoType = shared.BuyXGetY
+	case req.PromotionType == shared.Bundle.String():
+		promoType = shared.Bundle
 	}
 
 	var promoScope shared.VoucherScope
@@ -144,6 +162,8 @@ func RequestToManagePromotionDTO(req ManagePromotionRequest) (*ManagePromotion,
 		VoucherCode:    req.VoucherCode,
 		Amount:         amount,
 		Quota:          uint64(quota),
+		BuyQuantity:    req.BuyQuantity,
+		GetQuantity:    req.GetQuantity,
 		StartDate:      startDate,
 		EndDate:        endDate,
 	}
@@ -182,10 +202,12 @@ func (p *Promotion) ToListMerchantPromotionResponse() *ListMerchantPromotionResp
 		VoucherCode:    p.VoucherCode,
 		Amount:         p.Amount.String(),
 		Quota:          strconv.Itoa(int(p.Quota)),
+		BuyQuantity:    p.BuyQuantity,
+		GetQuantity:    p.GetQuantity,
 		StartDate:      p.StartDate.String(),
 		EndDate:        p.EndDate.String(),
 	}
-	if p.PromotionType.String() == shared.Discount.String() {
+	if p.PromotionType.String() == shared.Discount.String() || (p.PromotionType.String() == shared.FreeShipping.String() && p.MaxAmount != nil) {
 		strMaxAmount := p.MaxAmount.String()
 		res.MaxAmount = &strMaxAmount
 	}
@@ -205,3 +227,25 @@ func (p *Promotion) ToListMerchantPromotionResponse() *ListMerchantPromotionResp
 	}
 	return res
 }
+
+func ToPromotionReportResponse(promotion model.Promotion, total model.PromotionReport, daily []model.PromotionReport) *PromotionReportResponse {
+	res := &PromotionReportResponse{
+		PromotionID:   promotion.ID,
+		Name:          promotion.Name,
+		Redemptions:   total.Redemptions,
+		TotalDiscount: total.TotalDiscount.String(),
+		GMV:           total.GMV.String(),
+		UniqueBuyers:  total.UniqueBuyers,
+		Daily:         []PromotionReportDailyResponse{},
+	}
+	for _, d := range daily {
+		res.Daily = append(res.Daily, PromotionReportDailyResponse{
+			Date:          d.Date.Format("2006-01-02"),
+			Redemptions:   d.Redemptions,
+			TotalDiscount: d.TotalDiscount.String(),
+			GMV:           d.GMV.String(),
+			UniqueBuyers:  d.UniqueBuyers,
+		})
+	}
+	return res
+}
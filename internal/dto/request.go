@@ -2,6 +2,7 @@ package dto
 
 import (
 	"digital-test-vm/be/internal/shared"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -82,16 +83,20 @@ type ChangePhotoProfileRequest struct {
 }
 
 type ListProductQueries struct {
-	Keyword   string
-	Limit     uint64
-	Page      uint64
-	SortBy    string
-	SortOrder string
-	Category  string
-	MaxPrice  decimal.Decimal
-	MinPrice  decimal.Decimal
-	MinRating uint64
-	Locations []uint64
+	Keyword    string
+	Limit      uint64
+	Page       uint64
+	SortBy     string
+	SortOrder  string
+	Category   string
+	MaxPrice   decimal.Decimal
+	MinPrice   decimal.Decimal
+	MinRating  uint64
+	Locations  []uint64
+	WithFacets bool
+	ViewerID   *uint64
+	Cursor     string
+	ProductFilterQueries
 }
 
 type ListProductByMerchantQueries struct {
@@ -103,6 +108,15 @@ type ListProductByMerchantQueries struct {
 	Category         string
 	ExcludeNoStock   bool
 	ExcludeNotActive bool
+	Cursor           string
+	ProductFilterQueries
+}
+
+/* filters every product listing accepts, IsUsed is nil when both new and used products are wanted */
+type ProductFilterQueries struct {
+	IsUsed       *bool
+	HasPromotion bool
+	CourierIDs   []uint64
 }
 
 type AddProductToCartRequest struct {
@@ -122,6 +136,10 @@ type GetProductReviewRequest struct {
 	Limit     int    `json:"limit"`
 	Images    string `json:"images"`
 	Comments  string `json:"comments"`
+	Cursor    string `json:"cursor"`
+	SortBy    string `json:"sort_by"`
+	WithMedia bool   `json:"with_media"`
+	ViewerId  *uint64
 }
 
 type CreateProductReviewRequest struct {
@@ -132,10 +150,55 @@ type CreateProductReviewRequest struct {
 	Images        string `json:"images"`
 }
 
+type UpdateProductReviewRequest struct {
+	Rating   uint64   `json:"rating" binding:"required"`
+	Comments string   `json:"comments" binding:"max=1000"`
+	Images   []string `json:"images" binding:"max=5,dive,url"`
+}
+
 type DeactivateProductRequest struct {
 	ProductId uint64 `json:"product_id" binding:"required"`
 }
 
+/* a threshold of zero turns the low stock alert off */
+type UpdateStockAlertRequest struct {
+	LowStockThreshold *uint64 `json:"low_stock_threshold" binding:"required"`
+	AutoDeactivate    *bool   `json:"auto_deactivate" binding:"required"`
+}
+
+type StockMovementRequest struct {
+	ProductId uint64
+	VariantId uint64
+	Reason    string
+	Cursor    string
+	Limit     int
+}
+
+type ListProductQuestionRequest struct {
+	ProductId    uint64
+	AnsweredOnly bool
+	Cursor       string
+	Limit        int
+}
+
+type CreateProductQuestionRequest struct {
+	Question string `json:"question" binding:"required,max=500"`
+}
+
+type AnswerProductQuestionRequest struct {
+	Answer string `json:"answer" binding:"required,max=1000"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" binding:"required,max=1000"`
+}
+
+type ListNotificationRequest struct {
+	Cursor     string
+	Limit      int
+	UnreadOnly bool
+}
+
 type CreateWalletRequest struct {
 	Pin string `json:"pin" binding:"required,len=6,numeric"`
 }
@@ -163,6 +226,7 @@ type OrderStatus struct {
 type ListTransactionRequest struct {
 	Status string `json:"status"`
 	Page   uint64 `json:"page"`
+	Cursor string `json:"cursor"`
 }
 
 type ListSellerTransactionRequest struct {
@@ -180,22 +244,25 @@ type DecreasedQuantityToCartRequest struct {
 	Quantity uint64 `json:"quantity"`
 }
 
+/* a draft only needs a title and a category, everything that is filled in is still validated */
 type ManageProductRequest struct {
 	ID            uint64                      `json:"id,omitempty"`
 	Title         string                      `json:"title" binding:"required"`
-	Photos        []ManageProductPhotoRequest `json:"photos" binding:"required"`
+	Photos        []ManageProductPhotoRequest `json:"photos" binding:"required_unless=IsDraft true"`
 	Video         string                      `json:"video"`
-	Description   string                      `json:"description" binding:"required"`
-	Length        string                      `json:"length" binding:"required,numeric"`
-	Width         string                      `json:"width" binding:"required,numeric"`
-	Height        string                      `json:"height" binding:"required,numeric"`
-	Weight        string                      `json:"weight" binding:"required,numeric"`
+	Description   string                      `json:"description" binding:"required_unless=IsDraft true"`
+	Length        string                      `json:"length" binding:"required_unless=IsDraft true,omitempty,numeric"`
+	Width         string                      `json:"width" binding:"required_unless=IsDraft true,omitempty,numeric"`
+	Height        string                      `json:"height" binding:"required_unless=IsDraft true,omitempty,numeric"`
+	Weight        string                      `json:"weight" binding:"required_unless=IsDraft true,omitempty,numeric"`
 	IsUsed        bool                        `json:"is_used" binding:"boolean"`
 	IsHazardous   bool                        `json:"is_hazardous" binding:"boolean"`
 	CategoryLV1ID string                      `json:"category_lv1_id" binding:"required"`
 	CategoryLV2ID string                      `json:"category_lv2_id"`
 	CategoryLV3ID string                      `json:"category_lv3_id"`
-	Variants      ManageVariantRequest        `json:"variants" binding:"required"`
+	Variants      *ManageVariantRequest       `json:"variants" binding:"required_unless=IsDraft true"`
+	IsDraft       bool                        `json:"is_draft" binding:"boolean"`
+	PublishAt     *time.Time                  `json:"publish_at"`
 }
 
 func (r *ManageProductRequest) ToDTO() (*ManageProduct, error) {
@@ -204,29 +271,25 @@ func (r *ManageProductRequest) ToDTO() (*ManageProduct, error) {
 		photos = append(photos, *pr.ToDTO())
 	}
 
-	length, err := decimal.NewFromString(r.Length)
-	if err != nil {
-		return nil, err
-	}
-
-	width, err := decimal.NewFromString(r.Width)
-	if err != nil {
-		return nil, err
-	}
-
-	height, err := decimal.NewFromString(r.Height)
-	if err != nil {
-		return nil, err
-	}
-
-	weight, err := decimal.NewFromString(r.Weight)
-	if err != nil {
-		return nil, err
+	dimensions := []decimal.Decimal{}
+	for _, d := range []string{r.Length, r.Width, r.Height, r.Weight} {
+		if d == "" {
+			dimensions = append(dimensions, decimal.Zero)
+			continue
+		}
+		value, err := decimal.NewFromString(d)
+		if err != nil {
+			return nil, err
+		}
+		dimensions = append(dimensions, value)
 	}
 
-	variants, err := r.Variants.ToDTO()
-	if err != nil {
-		return nil, err
+	variants := &ManageVariant{Combinations: []ManageVariantCombination{}}
+	if r.Variants != nil {
+		var err error
+		if variants, err = r.Variants.ToDTO(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &ManageProduct{
@@ -235,16 +298,18 @@ func (r *ManageProductRequest) ToDTO() (*ManageProduct, error) {
 		Photos:        photos,
 		Video:         r.Video,
 		Description:   r.Description,
-		Length:        length,
-		Width:         width,
-		Height:        height,
-		Weight:        weight,
+		Length:        dimensions[0],
+		Width:         dimensions[1],
+		Height:        dimensions[2],
+		Weight:        dimensions[3],
 		IsUsed:        r.IsUsed,
 		IsHazardous:   r.IsHazardous,
 		CategoryLV1ID: r.CategoryLV1ID,
 		CategoryLV2ID: r.CategoryLV2ID,
 		CategoryLV3ID: r.CategoryLV3ID,
 		Variants:      *variants,
+		IsDraft:       r.IsDraft,
+		PublishAt:     r.PublishAt,
 	}, nil
 }
 
@@ -342,6 +407,32 @@ func (r *ManageVariantCombinationRequest) ToDTO() (*ManageVariantCombination, er
 	}, nil
 }
 
+type UpdateVariantRequest struct {
+	ID    uint64  `json:"id" binding:"required"`
+	Stock *uint64 `json:"stock"`
+	Price *string `json:"price"`
+}
+
+type UpdateVariantsRequest struct {
+	Variants []UpdateVariantRequest `json:"variants" binding:"required,min=1,dive"`
+}
+
+func (r *UpdateVariantsRequest) ToDTO() ([]UpdateVariant, error) {
+	variants := []UpdateVariant{}
+	for _, v := range r.Variants {
+		uv := UpdateVariant{ID: v.ID, Stock: v.Stock}
+		if v.Price != nil {
+			price, err := decimal.NewFromString(*v.Price)
+			if err != nil {
+				return nil, err
+			}
+			uv.Price = &price
+		}
+		variants = append(variants, uv)
+	}
+	return variants, nil
+}
+
 type MerchantCheckoutRequest struct {
 	MerchantId   uint64 `json:"merchant_id" binding:"required"`
 	CourierId    string `json:"courier_id" binding:"required"`
@@ -369,6 +460,8 @@ type ManagePromotionRequest struct {
 	Amount         string   `json:"amount" binding:"required"`
 	Quota          string   `json:"quota" binding:"required"`
 	MaxAmount      string   `json:"max_amount"`
+	BuyQuantity    uint64   `json:"buy_quantity"`
+	GetQuantity    uint64   `json:"get_quantity"`
 	Products       []uint64 `json:"products"`
 	StartDate      string   `json:"start_date" binding:"required"`
 	EndDate        string   `json:"end_date" binding:"required"`
@@ -388,3 +481,17 @@ type IsReviewRequest struct {
 type IsReviewResponse struct {
 	IsReview bool `json:"is_review"`
 }
+
+type ManageFlashSaleProductRequest struct {
+	VariantCombinationProductID uint64 `json:"variant_combination_product_id" binding:"required"`
+	SalePrice                   string `json:"sale_price" binding:"required"`
+	SaleStock                   uint64 `json:"sale_stock" binding:"required"`
+	PurchaseLimit               uint64 `json:"purchase_limit"`
+}
+
+type ManageFlashSaleRequest struct {
+	Name      string                          `json:"name" binding:"required"`
+	StartDate string                          `json:"start_date" binding:"required"`
+	EndDate   string                          `json:"end_date" binding:"required"`
+	Products  []ManageFlashSaleProductRequest `json:"products" binding:"required,min=1,dive"`
+}
@@ -42,6 +42,8 @@ type MerchantResponse struct {
 	User        *UserResponse `json:"user,omitempty"`
 	CreatedAt   time.Time     `json:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at"`
+
+	RatingBreakdown *model.MerchantRatingBreakdown `json:"rating_breakdown,omitempty"`
 }
 
 type UserResponse struct {
@@ -103,6 +105,8 @@ type ProductDetail struct {
 	Description   string                `json:"description"`
 	AverageRating float64               `json:"average_rating"`
 	TotalRating   uint64                `json:"total_rating"`
+	TotalQuestion int64                 `json:"total_question"`
+	TotalAnswered int64                 `json:"total_answered"`
 	Length        float64               `json:"length"`
 	Width         float64               `json:"width"`
 	Height        float64               `json:"height"`
@@ -115,6 +119,8 @@ type ProductDetail struct {
 	Category      model.CategoriesLv1   `json:"category_lv_1"`
 	MinPrice      decimal.Decimal       `json:"min_price"`
 	MaxPrice      decimal.Decimal       `json:"max_price"`
+	MinSalePrice  *decimal.Decimal      `json:"min_sale_price,omitempty"`
+	SaleEndDate   *time.Time            `json:"sale_end_date,omitempty"`
 	TotalSold     uint64                `json:"total_sold" gorm:"type:decimal(64,2);"`
 	Merchant      model.Merchant        `json:"merchant"`
 	Username      string                `json:"username"`
@@ -124,44 +130,63 @@ type ProductDetail struct {
 }
 
 type ProductReview struct {
-	ID             uint64    `json:"id"`
-	UserName       string    `json:"user_name"`
-	Rating         uint64    `json:"rating"`
-	ProfilePicture string    `json:"profile_picture"`
-	Images         []string  `json:"images"`
-	Description    string    `json:"comment"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uint64       `json:"id"`
+	UserName       string       `json:"user_name"`
+	Rating         uint64       `json:"rating"`
+	ProfilePicture string       `json:"profile_picture"`
+	Images         []string     `json:"images"`
+	Description    string       `json:"comment"`
+	Reply          *ReviewReply `json:"reply,omitempty"`
+	HelpfulCount   uint64       `json:"helpful_count"`
+	IsHelpful      bool         `json:"is_helpful"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type ReviewReply struct {
+	Text      string     `json:"text"`
+	RepliedAt time.Time  `json:"replied_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+func ToReviewReply(review model.ProductReview) *ReviewReply {
+	if review.Reply == nil || review.RepliedAt == nil {
+		return nil
+	}
+	return &ReviewReply{Text: *review.Reply, RepliedAt: *review.RepliedAt, EditedAt: review.ReplyEditedAt}
 }
 
 type Meta struct {
-	PaginationInfo PaginationInfo `json:"pagination_info,omitempty"`
+	PaginationInfo PaginationInfo         `json:"pagination_info,omitempty"`
+	Facets         *ProductFacetsResponse `json:"facets,omitempty"`
 }
 
 type PaginationInfo struct {
-	TotalItems  int64 `json:"total_items"`
-	TotalPages  int64 `json:"total_pages"`
-	CurrentPage int64 `json:"current_page"`
+	TotalItems  int64  `json:"total_items"`
+	TotalPages  int64  `json:"total_pages"`
+	CurrentPage int64  `json:"current_page"`
+	NextCursor  string `json:"next_cursor,omitempty"`
 }
 
 type ListProductsResponse struct {
-	ID            uint64          `json:"id"`
-	MerchantId    uint64          `json:"merchant_id"`
-	Username      string          `json:"username,omitempty"`
-	Title         string          `json:"title"`
-	Photo         string          `json:"photo"`
-	TotalSold     uint64          `json:"total_sold"`
-	FavCount      uint64          `json:"favorite_count"`
-	AverageRating float64         `json:"average_rating"`
-	TotalStock    uint64          `json:"total_stock"`
-	City          string          `json:"city"`
-	CategoryLv1Id string          `json:"category_lv1_id"`
-	CategoryLv2Id string          `json:"category_lv2_id"`
-	CategoryLv3Id string          `json:"category_lv3_id"`
-	MinPrice      decimal.Decimal `json:"min_price"`
-	IsActive      bool            `json:"is_active"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	ID             uint64           `json:"id"`
+	MerchantId     uint64           `json:"merchant_id"`
+	Username       string           `json:"username,omitempty"`
+	Title          string           `json:"title"`
+	Photo          string           `json:"photo"`
+	TotalSold      uint64           `json:"total_sold"`
+	FavCount       uint64           `json:"favorite_count"`
+	AverageRating  float64          `json:"average_rating"`
+	TotalStock     uint64           `json:"total_stock"`
+	City           string           `json:"city"`
+	CategoryLv1Id  string           `json:"category_lv1_id"`
+	CategoryLv2Id  string           `json:"category_lv2_id"`
+	CategoryLv3Id  string           `json:"category_lv3_id"`
+	MinPrice       decimal.Decimal  `json:"min_price"`
+	FlashSalePrice *decimal.Decimal `json:"flash_sale_price,omitempty"`
+	IsActive       bool             `json:"is_active"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
 }
 
 type CartResponse struct {
@@ -195,6 +220,8 @@ type ManageProductResponse struct {
 	CategoryLV3ID string                       `json:"category_lv3_id"`
 	Variants      ManageVariantResponse        `json:"variants" binding:"required"`
 	Couriers      []ManageCourierResponse      `json:"couriers" binding:"required"`
+	IsDraft       bool                         `json:"is_draft"`
+	PublishAt     *time.Time                   `json:"publish_at"`
 }
 
 type ManageProductPhotoResponse struct {
@@ -258,6 +285,7 @@ type CheckPriceMerchant struct {
 	Discount           string `json:"discount,omitempty"`
 	InitialPrice       string `json:"initial_price,omitempty"`
 	Ongkir             string `json:"ongkir"`
+	ShippingDiscount   string `json:"shipping_discount,omitempty"`
 	CheckPriceProducts []CheckPriceProduct
 }
 
@@ -269,11 +297,14 @@ type CheckPriceProduct struct {
 	CutPrice     string `json:"cut_price,omitempty"`
 	Discount     string `json:"discount,omitempty"`
 	InitialPrice string `json:"initial_price,omitempty"`
+	BundleName   string `json:"bundle_name,omitempty"`
+	BundleSaving string `json:"bundle_saving,omitempty"`
 }
 
 type ListOrder struct {
 	OrderId    uint64          `json:"order_id"`
 	OrderPrice decimal.Decimal `json:"order_price"`
+	CreatedAt  time.Time       `json:"-"`
 }
 type ListTransaction struct {
 	OrderId                     uint64          `json:"order_id"`
@@ -385,8 +416,60 @@ type ListMerchantPromotionResponse struct {
 	Amount         string                 `json:"amount" binding:"required"`
 	Quota          string                 `json:"quota" binding:"required"`
 	MaxAmount      *string                `json:"max_amount"`
+	BuyQuantity    uint64                 `json:"buy_quantity,omitempty"`
+	GetQuantity    uint64                 `json:"get_quantity,omitempty"`
 	Products       []ListProductsResponse `json:"products"`
 	StartDate      string                 `json:"start_date" binding:"required"`
 	EndDate        string                 `json:"end_date" binding:"required"`
 	Status         string                 `json:"promotion_status" binding:"required"`
 }
+
+type PromotionReportDailyResponse struct {
+	Date          string `json:"date"`
+	Redemptions   uint64 `json:"redemptions"`
+	TotalDiscount string `json:"total_discount"`
+	GMV           string `json:"gross_merchandise_value"`
+	UniqueBuyers  uint64 `json:"unique_buyers"`
+}
+
+type PromotionReportResponse struct {
+	PromotionID   uint64                         `json:"promotion_id"`
+	Name          string                         `json:"name"`
+	Redemptions   uint64                         `json:"redemptions"`
+	TotalDiscount string                         `json:"total_discount"`
+	GMV           string                         `json:"gross_merchandise_value"`
+	UniqueBuyers  uint64                         `json:"unique_buyers"`
+	Daily         []PromotionReportDailyResponse `json:"daily"`
+}
+
+type ProductSuggestionResponse struct {
+	Products   []string `json:"products"`
+	Categories []string `json:"categories"`
+	Merchants  []string `json:"merchants"`
+}
+
+type ProductFacetBucketResponse struct {
+	Value string  `json:"value"`
+	Label string  `json:"label"`
+	Count uint64  `json:"count"`
+	Min   *string `json:"min,omitempty"`
+	Max   *string `json:"max,omitempty"`
+}
+
+type ProductFacetsResponse struct {
+	CategoryLv1 []ProductFacetBucketResponse `json:"category_lv1"`
+	CategoryLv2 []ProductFacetBucketResponse `json:"category_lv2"`
+	CategoryLv3 []ProductFacetBucketResponse `json:"category_lv3"`
+	Locations   []ProductFacetBucketResponse `json:"locations"`
+	Ratings     []ProductFacetBucketResponse `json:"ratings"`
+	Prices      []ProductFacetBucketResponse `json:"prices"`
+}
+
+type MediaUploadResponse struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int    `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
@@ -25,6 +25,7 @@ type UserInfo struct {
 	PhoneNumber   string
 	Email      string
 	IsSeller   bool
+	IsAdmin    bool
 	CartId     uint64
 	WalletId   *string
 	MerchantId *uint64
@@ -76,3 +76,10 @@ func (mvc *ManageVariantCombination) ToResponse() *ManageVariantCombinationRespo
 	}
 	return mvcr
 }
+
+/* new stock and/or price of a variant combination product, nil fields are left as they are */
+type UpdateVariant struct {
+	ID    uint64
+	Stock *uint64
+	Price *decimal.Decimal
+}
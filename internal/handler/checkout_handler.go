@@ -2,6 +2,7 @@ package handler
 
 import (
 	"digital-test-vm/be/internal/dto"
+	repo "digital-test-vm/be/internal/repository"
 	"digital-test-vm/be/internal/shared"
 	"digital-test-vm/be/internal/usecase"
 	"digital-test-vm/be/internal/utils"
@@ -36,6 +37,9 @@ func (h *CheckoutHandler) GetPromoHandler(c *gin.Context) {
 	promo, err := h.usecase.CheckoutUsecase.GetAvailablePromo(ctx, *user)
 	if err != nil {
 		httpError := shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrVariantNotFound){
+			httpError = shared.ErrVariantNotFound
+		}
 		httpError.InternalError = err
 		_ = c.Error(&httpError)
 		return
@@ -73,6 +77,15 @@ func (h *CheckoutHandler) CheckoutCartHandler(c *gin.Context) {
 		if errors.Is(err, usecase.ErrCartEmpty){
 			httpError = shared.ErrCartEmpty
 		}
+		if errors.Is(err, repo.ErrFlashSaleSoldOut){
+			httpError = shared.ErrFlashSaleSoldOut
+		}
+		if errors.Is(err, repo.ErrVariantNotFound){
+			httpError = shared.ErrVariantNotFound
+		}
+		if errors.Is(err, usecase.ErrFlashSaleLimitExceeded){
+			httpError = shared.ErrFlashSaleLimitExceeded
+		}
 		if errors.Is(err, usecase.ErrInvalidAddress){
 			httpError = shared.ErrCartEmpty
 		}
@@ -113,6 +126,15 @@ func (h *CheckoutHandler) CheckPriceHandler(c *gin.Context) {
 		if errors.Is(err, usecase.ErrCartEmpty){
 			httpError = shared.ErrCartEmpty
 		}
+		if errors.Is(err, repo.ErrFlashSaleSoldOut){
+			httpError = shared.ErrFlashSaleSoldOut
+		}
+		if errors.Is(err, repo.ErrVariantNotFound){
+			httpError = shared.ErrVariantNotFound
+		}
+		if errors.Is(err, usecase.ErrFlashSaleLimitExceeded){
+			httpError = shared.ErrFlashSaleLimitExceeded
+		}
 		httpError.InternalError = err
 		_ = c.Error(&httpError)
 		return
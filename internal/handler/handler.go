@@ -18,6 +18,9 @@ type Handler struct {
 	OrderHandler           *OrderHandler
 	CheckoutHandler        *CheckoutHandler
 	PromotionHandler       *PromotionHandler
+	MediaHandler           *MediaHandler
+	NotificationHandler    *NotificationHandler
+	ProductQuestionHandler *ProductQuestionHandler
 }
 
 func NewHandler(usecase *usecase.Usecase) *Handler {
@@ -35,5 +38,8 @@ func NewHandler(usecase *usecase.Usecase) *Handler {
 		OrderHandler:           NewOrderHandler(usecase),
 		CheckoutHandler:        NewCheckoutHandler(usecase),
 		PromotionHandler:       NewPromotionHandler(usecase),
+		MediaHandler:           NewMediaHandler(usecase),
+		NotificationHandler:    NewNotificationHandler(usecase),
+		ProductQuestionHandler: NewProductQuestionHandler(usecase),
 	}
 }
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/shared"
+	"digital-test-vm/be/internal/usecase"
+	"digital-test-vm/be/internal/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* room for the multipart boundaries and the other form fields around the file */
+const mediaFormOverhead = 1 << 20
+
+type MediaHandler struct {
+	usecase *usecase.Usecase
+}
+
+func NewMediaHandler(usecase *usecase.Usecase) *MediaHandler {
+	return &MediaHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *MediaHandler) UploadHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if c.Request.ContentLength > usecase.MediaMaxUploadSize+mediaFormOverhead {
+		httpError = shared.ErrMediaTooLarge
+		_ = c.Error(&httpError)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, usecase.MediaMaxUploadSize+mediaFormOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if fileHeader.Size > usecase.MediaMaxUploadSize {
+		httpError = shared.ErrMediaTooLarge
+		_ = c.Error(&httpError)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	res, err := h.usecase.MediaUsecase.UploadImage(ctx, user.ID, c.PostForm("kind"), body)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidMediaKind):
+			httpError = shared.ErrInvalidMediaKind
+		case errors.Is(err, usecase.ErrMediaTooLarge):
+			httpError = shared.ErrMediaTooLarge
+		case errors.Is(err, usecase.ErrUnsupportedMediaType):
+			httpError = shared.ErrUnsupportedMediaType
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.JSONResponse{Data: res})
+}
@@ -36,6 +36,8 @@ func (h *MerchantHandler) GetMerchantHandler(c *gin.Context) {
 	if err != nil {
 		if utils.CheckErrorIn(err, repo.ErrMerchantNotFound, gorm.ErrRecordNotFound) {
 			httpErr = shared.ErrMerchantNotFound
+		} else if errors.Is(err, repo.ErrInvalidCursor) {
+			httpErr = shared.ErrInvalidCursor
 		} else {
 			httpErr = shared.ErrInternalServerError
 		}
@@ -133,6 +135,10 @@ func getListProductsByMerchantQueries(c *gin.Context) *dto.ListProductByMerchant
 	default:
 		excludeNoStockStatus = shared.False.Bool()
 	}
+	/* in_stock is the same filter under the name the public listing uses */
+	if c.Query("in_stock") == shared.True.String() {
+		excludeNoStockStatus = shared.True.Bool()
+	}
 
 	excludeNotActive := c.Query("exclude_not_active")
 	var excludeNotActiveStatus bool
@@ -152,6 +158,9 @@ func getListProductsByMerchantQueries(c *gin.Context) *dto.ListProductByMerchant
 		Category:         strings.ToUpper(c.Query("category")),
 		ExcludeNoStock:   excludeNoStockStatus,
 		ExcludeNotActive: excludeNotActiveStatus,
+		Cursor:           c.Query("cursor"),
+
+		ProductFilterQueries: getProductFilterQueries(c),
 	}
 }
 
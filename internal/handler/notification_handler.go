@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"digital-test-vm/be/internal/dto"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/shared"
+	"digital-test-vm/be/internal/usecase"
+	"digital-test-vm/be/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	usecase *usecase.Usecase
+}
+
+func NewNotificationHandler(usecase *usecase.Usecase) *NotificationHandler {
+	return &NotificationHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	req := dto.ListNotificationRequest{
+		Cursor:     c.Query("cursor"),
+		Limit:      limit,
+		UnreadOnly: c.Query("unread") == shared.True.String(),
+	}
+	res, nextCursor, err := h.usecase.NotificationUsecase.GetNotifications(ctx, user.ID, req)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpError = shared.ErrInvalidCursor
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: dto.PaginationInfo{NextCursor: nextCursor}}})
+}
+
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.NotificationUsecase.MarkNotificationRead(ctx, user.ID, id); err != nil {
+		httpError = shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrNotificationNotFound) {
+			httpError = shared.ErrNotificationNotFound
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.NotificationUsecase.MarkAllNotificationsRead(ctx, user.ID); err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "all notifications marked as read"})
+}
@@ -44,12 +44,15 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		_ = c.Error(&httpErr)
 		return
 	}
-	req := dto.ListTransactionRequest{Status: status, Page: uint64(pageNumber)}
-	res, err := h.usecase.OrderUsecase.GetListTransaction(ctx, req, user.CartId, user.ID)
+	req := dto.ListTransactionRequest{Status: status, Page: uint64(pageNumber), Cursor: c.Query("cursor")}
+	res, nextCursor, err := h.usecase.OrderUsecase.GetListTransaction(ctx, req, user.CartId, user.ID)
 	if err != nil {
 		if errors.Is(err, usecase.ErrListTransactionNotFound) {
 			httpErr = shared.ErrListTransactionNotFound
 		}
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpErr = shared.ErrInvalidCursor
+		}
 		if errors.Is(err, usecase.ErrInternalServerError) {
 			httpErr = shared.ErrInternalServerError
 		}
@@ -67,6 +70,7 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		_ = c.Error(&httpErr)
 		return
 	}
+	pagination.NextCursor = nextCursor
 	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: *pagination}})
 }
 
@@ -389,3 +393,44 @@ func (h *OrderHandler) ChangeOrderStatusToReviewed(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.JSONResponse{Message: "success update order status"})
 }
+
+func (h *OrderHandler) ChangeOrderStatusToCanceled(c *gin.Context) {
+	var httpErr shared.HTTPError
+
+	ctx := c.Request.Context()
+	var req dto.ChangeStatusOrderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpErr = shared.ErrBadRequest
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpErr := shared.ErrClaimsNotFound
+		httpErr.InternalError = fmt.Errorf("OrderHandler/ChangeOrderStatusToCanceled: %w", shared.ErrClaimsNotFound)
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	if user.MerchantId == nil {
+		httpErr = shared.ErrUnauthorizedAccess
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	if err := h.usecase.OrderUsecase.ChangeOrderStatusToCanceled(ctx, req.OrderDetailId, *user.MerchantId); err != nil {
+		httpErr := shared.ErrInternalServerError
+		if errors.Is(err, usecase.ErrUnauthorizedAccess) {
+			httpErr = shared.ErrUnauthorizedAccess
+		}
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "success cancel order"})
+}
@@ -6,6 +6,7 @@ import (
 	"digital-test-vm/be/internal/shared"
 	"digital-test-vm/be/internal/usecase"
 	"digital-test-vm/be/internal/utils"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"net/http"
@@ -32,9 +33,43 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	resData, resMetaPageInfo, err := h.usecase.ProductUsecase.ListProducts(ctx, *getListProductsQueries(c))
+	resData, resMetaPageInfo, resFacets, err := h.usecase.ProductUsecase.ListProducts(ctx, *getListProductsQueries(c))
 	if err != nil {
-		httpErr = shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpErr = shared.ErrInvalidCursor
+		} else {
+			httpErr = shared.ErrInternalServerError
+		}
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: fillListProductsResponse(resData), Meta: &dto.Meta{PaginationInfo: *resMetaPageInfo, Facets: resFacets}})
+}
+
+func (h *ProductHandler) ForYouProducts(c *gin.Context) {
+	var httpErr shared.HTTPError
+
+	ctx := c.Request.Context()
+
+	p, err := strconv.Atoi(c.Query("page"))
+	if err != nil || p < 1 {
+		p = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 30
+	}
+
+	resData, resMetaPageInfo, err := h.usecase.ProductUsecase.ListForYouProducts(ctx, utils.GetViewerIDFromContext(c), c.Query("cursor"), uint64(p), uint64(limit))
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpErr = shared.ErrInvalidCursor
+		} else {
+			httpErr = shared.ErrInternalServerError
+		}
 		httpErr.InternalError = err
 		_ = c.Error(&httpErr)
 		return
@@ -43,6 +78,96 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.JSONResponse{Data: fillListProductsResponse(resData), Meta: &dto.Meta{PaginationInfo: *resMetaPageInfo}})
 }
 
+func (h *ProductHandler) RelatedProducts(c *gin.Context) {
+	var httpErr shared.HTTPError
+
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httpErr = shared.ErrPageNotFound
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 12
+	}
+	if limit > 30 {
+		limit = 30
+	}
+
+	resData, err := h.usecase.ProductUsecase.ListRelatedProducts(ctx, uint64(id), uint64(limit))
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrProductNotFound):
+			httpErr = shared.ErrProductNotFound
+		default:
+			httpErr = shared.ErrInternalServerError
+		}
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: fillListProductsResponse(resData)})
+}
+
+func (h *ProductHandler) RecentlyViewedProducts(c *gin.Context) {
+	var httpErr shared.HTTPError
+
+	ctx := c.Request.Context()
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpErr = shared.ErrUnauthorizedAccess
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	resData, err := h.usecase.ProductUsecase.ListRecentlyViewedProducts(ctx, user.ID, int64(limit))
+	if err != nil {
+		httpErr = shared.ErrInternalServerError
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: fillListProductsResponse(resData)})
+}
+
+func (h *ProductHandler) SuggestProducts(c *gin.Context) {
+	var httpErr shared.HTTPError
+
+	ctx := c.Request.Context()
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+	if limit > 10 {
+		limit = 10
+	}
+
+	resData, err := h.usecase.ProductUsecase.SuggestProducts(ctx, c.Query("q"), int64(limit))
+	if err != nil {
+		httpErr = shared.ErrInternalServerError
+		httpErr.InternalError = err
+		_ = c.Error(&httpErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: resData})
+}
+
 func getListProductsQueries(c *gin.Context) *dto.ListProductQueries {
 	p, err := strconv.Atoi(c.Query("page"))
 	if err != nil {
@@ -81,10 +206,21 @@ func getListProductsQueries(c *gin.Context) *dto.ListProductQueries {
 	}
 
 	sortCriteria := c.Query("sort_by")
-	if !utils.CheckValueStringIn(sortCriteria, shared.TotalSold.String(), shared.Rating.String(), shared.Date.String(), shared.Price.String()) {
-		sortCriteria = shared.Recommended.Translate()
+	if !utils.CheckValueStringIn(sortCriteria, shared.TotalSold.String(), shared.Rating.String(), shared.Date.String(), shared.Price.String(), shared.Relevance.String(), shared.Recommended.String(), shared.Trending.String()) {
+		/* searches are ranked by relevance unless another sort is asked for */
+		if strings.TrimSpace(c.Query("q")) != "" {
+			sortCriteria = shared.Relevance.Translate()
+		} else {
+			sortCriteria = shared.Recommended.Translate()
+		}
 	} else {
 		switch sortCriteria {
+		case shared.Relevance.String():
+			sortCriteria = shared.Relevance.Translate()
+		case shared.Recommended.String():
+			sortCriteria = shared.Recommended.Translate()
+		case shared.Trending.String():
+			sortCriteria = shared.Trending.Translate()
 		case shared.TotalSold.String():
 			sortCriteria = shared.TotalSold.Translate()
 		case shared.Rating.String():
@@ -98,24 +234,54 @@ func getListProductsQueries(c *gin.Context) *dto.ListProductQueries {
 
 	sortOrder := strings.ToUpper(c.Query("sort"))
 	if !utils.CheckValueStringIn(sortOrder, shared.ASC.String(), shared.DESC.String()) {
-		if utils.CheckValueStringIn(sortCriteria, shared.Rating.Translate(), shared.TotalSold.Translate()) {
+		if utils.CheckValueStringIn(sortCriteria, shared.Rating.Translate(), shared.TotalSold.Translate(), shared.Trending.Translate()) {
 			sortOrder = shared.DESC.String()
 		} else {
 			sortOrder = shared.ASC.String()
 		}
 	}
 
+	withFacets, _ := strconv.ParseBool(c.Query("facets"))
+
 	return &dto.ListProductQueries{
-		Keyword:   c.Query("q"),
-		MinRating: uint64(minRating),
-		MinPrice:  minPrice,
-		MaxPrice:  maxPrice,
-		Locations: locationIDs,
-		SortBy:    sortCriteria,
-		SortOrder: sortOrder,
-		Page:      uint64(p),
-		Limit:     uint64(limit),
-		Category:  strings.ToUpper(c.Query("category")),
+		Keyword:    c.Query("q"),
+		MinRating:  uint64(minRating),
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		Locations:  locationIDs,
+		SortBy:     sortCriteria,
+		SortOrder:  sortOrder,
+		Page:       uint64(p),
+		Limit:      uint64(limit),
+		Category:   strings.ToUpper(c.Query("category")),
+		WithFacets: withFacets,
+		ViewerID:   utils.GetViewerIDFromContext(c),
+		Cursor:     c.Query("cursor"),
+
+		ProductFilterQueries: getProductFilterQueries(c),
+	}
+}
+
+func getProductFilterQueries(c *gin.Context) dto.ProductFilterQueries {
+	var isUsed *bool
+	if used, err := strconv.ParseBool(c.Query("is_used")); err == nil {
+		isUsed = &used
+	}
+
+	hasPromotion, _ := strconv.ParseBool(c.Query("has_promotion"))
+
+	courierIDs := []uint64{}
+	for _, courier := range strings.Split(c.Query("courier"), ",") {
+		courierID, err := strconv.ParseUint(strings.TrimSpace(courier), 10, 64)
+		if err == nil {
+			courierIDs = append(courierIDs, courierID)
+		}
+	}
+
+	return dto.ProductFilterQueries{
+		IsUsed:       isUsed,
+		HasPromotion: hasPromotion,
+		CourierIDs:   courierIDs,
 	}
 }
 
@@ -131,7 +297,7 @@ func (h *ProductHandler) ProductDetail(c *gin.Context) {
 		return
 	}
 
-	res, err := h.usecase.ProductUsecase.GetDetailProduct(ctx, uint64(id))
+	res, err := h.usecase.ProductUsecase.GetDetailProduct(ctx, uint64(id), utils.GetViewerIDFromContext(c), c.ClientIP())
 
 	if err != nil {
 		if errors.Is(err, repo.ErrProductNotFound) {
@@ -212,6 +378,10 @@ func (h *ProductHandler) ActivateProduct(c *gin.Context) {
 	if err := h.usecase.ProductUsecase.ActivateProduct(ctx, req.ProductId, user.ID); err != nil {
 		if errors.Is(err, usecase.ErrUnauthorizedProduct) {
 			httpError = shared.ErrUnauthorizedAccess
+		} else if errors.Is(err, usecase.ErrProductIsDraft) {
+			httpError = shared.ErrProductIsDraft
+		} else if errors.Is(err, usecase.ErrProductNotFound) {
+			httpError = shared.ErrProductNotFound
 		}
 		httpError.InternalError = err
 		_ = c.Error(&httpError)
@@ -251,6 +421,9 @@ func (h *ProductHandler) CreateProductHandler(c *gin.Context) {
 	err = h.usecase.ProductUsecase.CreateProduct(ctx, user.ID, createProductDTO)
 	if err != nil {
 		httpError = shared.ErrInternalServerError
+		if errors.Is(err, usecase.ErrProductIncomplete) {
+			httpError = shared.ErrProductIncomplete
+		}
 		httpError.InternalError = err
 		_ = c.Error(&httpError)
 		return
@@ -259,6 +432,418 @@ func (h *ProductHandler) CreateProductHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully created product"})
 }
 
+func (h *ProductHandler) ImportProductsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	defer file.Close()
+
+	batch, err := dto.ParseProductCSV(file)
+	if err != nil {
+		if errors.Is(err, dto.ErrProductCSVTooLong) {
+			httpError = shared.ErrProductCSVTooLong
+		} else {
+			httpError = shared.ErrInvalidProductCSV
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	report := h.usecase.ProductUsecase.ImportProducts(ctx, user, batch)
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Data: report})
+}
+
+func (h *ProductHandler) ExportProductsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	products, err := h.usecase.ProductUsecase.ExportProducts(ctx, user)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="products.csv"`)
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(dto.ProductCSVHeader)
+	for _, p := range products {
+		_ = w.WriteAll(p.ToCSVRecords())
+	}
+	w.Flush()
+}
+
+func (h *ProductHandler) UpdateVariantsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.UpdateVariantsRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	variants, err := req.ToDTO()
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	err = h.usecase.ProductUsecase.UpdateVariants(ctx, user, variants)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidVariantUpdate):
+			httpError = shared.ErrBadRequest
+		case errors.Is(err, repo.ErrVariantNotFound):
+			httpError = shared.ErrVariantNotFound
+		case errors.Is(err, repo.ErrVariantNotOwned), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully updated variants"})
+}
+
+func (h *ProductHandler) UpdateStockAlertHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.UpdateStockAlertRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	id := c.Param("id")
+	productID, err := strconv.Atoi(id)
+	if err != nil {
+		httpError = shared.ErrProductNotFound
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	err = h.usecase.StockAlertUsecase.UpdateStockAlert(ctx, user, uint64(productID), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully updated stock alert"})
+}
+
+func (h *ProductHandler) StockHistoryHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	id := c.Param("id")
+	productID, err := strconv.Atoi(id)
+	if err != nil {
+		httpError = shared.ErrProductNotFound
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	variantID, err := strconv.ParseUint(c.DefaultQuery("variant_id", "0"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	req := dto.StockMovementRequest{
+		ProductId: uint64(productID),
+		VariantId: variantID,
+		Reason:    c.Query("reason"),
+		Cursor:    c.Query("cursor"),
+		Limit:     limit,
+	}
+	movements, nextCursor, err := h.usecase.ProductUsecase.GetStockHistory(ctx, user, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		case errors.Is(err, repo.ErrInvalidCursor):
+			httpError = shared.ErrInvalidCursor
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Data: movements, Meta: &dto.Meta{PaginationInfo: dto.PaginationInfo{NextCursor: nextCursor}}})
+}
+
+func (h *ProductHandler) DraftProductsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	drafts, err := h.usecase.ProductUsecase.GetDraftProducts(ctx, user)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		if errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant) {
+			httpError = shared.ErrForbiddenResource
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Data: drafts})
+}
+
+func (h *ProductHandler) DeleteProductHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	id := c.Param("id")
+	productID, err := strconv.Atoi(id)
+	if err != nil {
+		httpError = shared.ErrProductNotFound
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	err = h.usecase.ProductUsecase.DeleteProduct(ctx, user, uint64(productID))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound), errors.Is(err, repo.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully deleted product"})
+}
+
+func (h *ProductHandler) RestoreProductHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	id := c.Param("id")
+	productID, err := strconv.Atoi(id)
+	if err != nil {
+		httpError = shared.ErrProductNotFound
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	err = h.usecase.ProductUsecase.RestoreProduct(ctx, user, uint64(productID))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, repo.ErrProductNotArchived):
+			httpError = shared.ErrProductNotArchived
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully restored product"})
+}
+
+func (h *ProductHandler) ArchivedProductsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	products, err := h.usecase.ProductUsecase.GetArchivedProducts(ctx, user)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		if errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant) {
+			httpError = shared.ErrForbiddenResource
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Data: products})
+}
+
 func (h *ProductHandler) EditProductHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 	var httpError shared.HTTPError
@@ -352,8 +937,10 @@ func (h *ProductHandler) UpdateProductHandler(c *gin.Context) {
 	}
 	err = h.usecase.ProductUsecase.UpdateProduct(ctx, user, updateProductDTO)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, usecase.ErrProductNotFound) {
 			httpError = shared.ErrProductNotFound
+		} else if errors.Is(err, usecase.ErrProductIncomplete) {
+			httpError = shared.ErrProductIncomplete
 		} else {
 			httpError = shared.ErrInternalServerError
 		}
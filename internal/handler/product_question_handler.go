@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"digital-test-vm/be/internal/dto"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/shared"
+	"digital-test-vm/be/internal/usecase"
+	"digital-test-vm/be/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductQuestionHandler struct {
+	usecase *usecase.Usecase
+}
+
+func NewProductQuestionHandler(usecase *usecase.Usecase) *ProductQuestionHandler {
+	return &ProductQuestionHandler{
+		usecase: usecase,
+	}
+}
+
+func (h *ProductQuestionHandler) GetProductQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	productId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	req := dto.ListProductQuestionRequest{
+		ProductId:    productId,
+		AnsweredOnly: c.Query("answered") == shared.True.String(),
+		Cursor:       c.Query("cursor"),
+		Limit:        limit,
+	}
+	res, nextCursor, err := h.usecase.ProductQuestionUsecase.GetProductQuestions(ctx, req)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpError = shared.ErrInvalidCursor
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: dto.PaginationInfo{NextCursor: nextCursor}}})
+}
+
+func (h *ProductQuestionHandler) CreateProductQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.CreateProductQuestionRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	productId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	res, err := h.usecase.ProductQuestionUsecase.CreateQuestion(ctx, user.ID, productId, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrBlankText):
+			httpError = shared.ErrBlankText
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.JSONResponse{Data: res, Message: "question posted"})
+}
+
+func (h *ProductQuestionHandler) AnswerProductQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.AnswerProductQuestionRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	questionId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.ProductQuestionUsecase.AnswerQuestion(ctx, user, questionId, req); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrQuestionNotFound):
+			httpError = shared.ErrQuestionNotFound
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrBlankText):
+			httpError = shared.ErrBlankText
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "question answered"})
+}
+
+func (h *ProductQuestionHandler) GetUnansweredQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	res, nextCursor, err := h.usecase.ProductQuestionUsecase.GetUnansweredQuestions(ctx, user, c.Query("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrInvalidCursor):
+			httpError = shared.ErrInvalidCursor
+		case errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: dto.PaginationInfo{NextCursor: nextCursor}}})
+}
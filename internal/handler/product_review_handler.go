@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"digital-test-vm/be/internal/dto"
 	repo "digital-test-vm/be/internal/repository"
 	"digital-test-vm/be/internal/shared"
@@ -60,6 +61,10 @@ func (h *ProductReviewHandler) GetProductReview(c *gin.Context) {
 	comment := c.DefaultQuery("comment", "")
 	images := c.DefaultQuery("images", "")
 	order := c.DefaultQuery("sort", "desc")
+	sortBy := ""
+	if order == shared.ReviewSortHelpful.String() {
+		sortBy = order
+	}
 	if order == "oldest" {
 		order = "asc"
 	} else {
@@ -74,11 +79,18 @@ func (h *ProductReviewHandler) GetProductReview(c *gin.Context) {
 		Limit:     limit,
 		Images:    images,
 		Comments:  comment,
+		Cursor:    c.Query("cursor"),
+		SortBy:    sortBy,
+		WithMedia: c.Query("with_media") == shared.True.String(),
+		ViewerId:  utils.GetViewerIDFromContext(c),
 	}
-	res, err := h.usecase.ProductReviewUsecase.GetProductReview(ctx, req)
+	res, nextCursor, err := h.usecase.ProductReviewUsecase.GetProductReview(ctx, req)
 
 	if err != nil {
 		httpErr := shared.ErrInternalServerError
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			httpErr = shared.ErrInvalidCursor
+		}
 		httpErr.InternalError = err
 		_ = c.Error(&httpErr)
 		return
@@ -92,6 +104,7 @@ func (h *ProductReviewHandler) GetProductReview(c *gin.Context) {
 		return
 	}
 	pagination.CurrentPage = int64(page)
+	pagination.NextCursor = nextCursor
 
 	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: pagination}})
 }
@@ -177,3 +190,243 @@ func (h *ProductReviewHandler) IsReviewed(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, dto.JSONResponse{Data: dto.IsReviewResponse{IsReview: valid}})
 }
+
+func (h *ProductReviewHandler) ReplyReview(c *gin.Context) {
+	h.saveReviewReply(c, h.usecase.ProductReviewUsecase.ReplyReview, "reply posted")
+}
+
+func (h *ProductReviewHandler) EditReviewReply(c *gin.Context) {
+	h.saveReviewReply(c, h.usecase.ProductReviewUsecase.EditReviewReply, "reply updated")
+}
+
+/* posting and editing a reply take the same input and fail the same way */
+func (h *ProductReviewHandler) saveReviewReply(c *gin.Context, save func(context.Context, *dto.UserInfo, uint64, dto.ReviewReplyRequest) error, message string) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.ReviewReplyRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	reviewId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := save(ctx, user, reviewId, req); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrReviewNotFound):
+			httpError = shared.ErrReviewNotFound
+		case errors.Is(err, repo.ErrReviewReplied):
+			httpError = shared.ErrReviewReplied
+		case errors.Is(err, repo.ErrReplyNotEditable):
+			httpError = shared.ErrReplyNotEditable
+		case errors.Is(err, usecase.ErrBlankText):
+			httpError = shared.ErrBlankText
+		case errors.Is(err, usecase.ErrProductNotFound):
+			httpError = shared.ErrProductNotFound
+		case errors.Is(err, usecase.ErrUnauthorizedProduct), errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: message})
+}
+
+func (h *ProductReviewHandler) GetMerchantReviews(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	unrepliedOnly := c.Query("unreplied") == shared.True.String()
+	res, nextCursor, err := h.usecase.ProductReviewUsecase.GetMerchantReviews(ctx, user, c.Query("cursor"), limit, unrepliedOnly)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrInvalidCursor):
+			httpError = shared.ErrInvalidCursor
+		case errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: dto.PaginationInfo{NextCursor: nextCursor}}})
+}
+
+func (h *ProductReviewHandler) MarkReviewHelpful(c *gin.Context) {
+	h.voteReviewHelpful(c, true, "review marked as helpful")
+}
+
+func (h *ProductReviewHandler) UnmarkReviewHelpful(c *gin.Context) {
+	h.voteReviewHelpful(c, false, "helpful vote removed")
+}
+
+func (h *ProductReviewHandler) voteReviewHelpful(c *gin.Context, helpful bool, message string) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	reviewId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.ProductReviewUsecase.VoteHelpful(ctx, reviewId, user.ID, helpful); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrReviewNotFound):
+			httpError = shared.ErrReviewNotFound
+		case errors.Is(err, usecase.ErrVoteOwnReview):
+			httpError = shared.ErrVoteOwnReview
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: message})
+}
+
+func (h *ProductReviewHandler) UpdateProductReview(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.UpdateProductReviewRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	reviewId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.ProductReviewUsecase.UpdateProductReview(ctx, reviewId, user.ID, req); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrReviewNotFound):
+			httpError = shared.ErrReviewNotFound
+		case errors.Is(err, usecase.ErrRatingNotValid):
+			httpError = shared.ErrRatingNotValid
+		case errors.Is(err, usecase.ErrReviewExpired):
+			httpError = shared.ErrReviewExpired
+		case errors.Is(err, usecase.ErrNotReviewAuthor):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "review updated"})
+}
+
+func (h *ProductReviewHandler) DeleteProductReview(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	reviewId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpError = shared.ErrConvertToInteger
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.ProductReviewUsecase.DeleteProductReview(ctx, reviewId, user.ID); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrReviewNotFound):
+			httpError = shared.ErrReviewNotFound
+		case errors.Is(err, usecase.ErrNotReviewAuthor):
+			httpError = shared.ErrForbiddenResource
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Message: "review deleted"})
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
 	"digital-test-vm/be/internal/dto"
+	repo "digital-test-vm/be/internal/repository"
 	"digital-test-vm/be/internal/shared"
 	"digital-test-vm/be/internal/usecase"
 	"digital-test-vm/be/internal/utils"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -73,6 +76,14 @@ func (h *PromotionHandler) CreateMerchantPromotion(c *gin.Context) {
 			httpError = shared.ErrBadRequest
 			httpError.Message = usecase.ErrInvalidAmountForDiscountTypePromo.Error()
 
+		case errors.Is(err, usecase.ErrInvalidAmountForShippingTypePromo):
+			httpError = shared.ErrBadRequest
+			httpError.Message = usecase.ErrInvalidAmountForShippingTypePromo.Error()
+
+		case errors.Is(err, usecase.ErrInvalidBundlePromotion):
+			httpError = shared.ErrBadRequest
+			httpError.Message = usecase.ErrInvalidBundlePromotion.Error()
+
 		default:
 			httpError = shared.ErrInternalServerError
 		}
@@ -84,3 +95,280 @@ func (h *PromotionHandler) CreateMerchantPromotion(c *gin.Context) {
 
 	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully created promotion"})
 }
+
+func (h *PromotionHandler) CreateGlobalPromotion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.ManagePromotionRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if !user.IsAdmin {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	createPromotionDTO, err := dto.RequestToManagePromotionDTO(req)
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.PromotionUsecase.CreateGlobalPromotion(ctx, createPromotionDTO); err != nil {
+		httpError = globalPromotionError(err)
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully created promotion"})
+}
+
+func (h *PromotionHandler) UpdateGlobalPromotion(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.ManagePromotionRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if !user.IsAdmin {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	promoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || promoID <= 0 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+	req.ID = uint64(promoID)
+
+	updatePromotionDTO, err := dto.RequestToManagePromotionDTO(req)
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.PromotionUsecase.UpdateGlobalPromotion(ctx, updatePromotionDTO); err != nil {
+		httpError = globalPromotionError(err)
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully updated promotion"})
+}
+
+func (h *PromotionHandler) ListGlobalPromotions(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if !user.IsAdmin {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	h.listGlobalPromotions(c, ctx, getListPromotionQueries(c))
+}
+
+func (h *PromotionHandler) ListOngoingGlobalPromotions(c *gin.Context) {
+	listPromotionQueries := getListPromotionQueries(c)
+	listPromotionQueries.Status = shared.PromotionStatusOngoing
+
+	h.listGlobalPromotions(c, c.Request.Context(), listPromotionQueries)
+}
+
+func (h *PromotionHandler) listGlobalPromotions(c *gin.Context, ctx context.Context, listPromotionQueries dto.ListPromotionQueries) {
+	var httpError shared.HTTPError
+
+	promotions, pageInfo, err := h.usecase.PromotionUsecase.ListGlobalPromotions(ctx, listPromotionQueries)
+	if err != nil {
+		httpError = shared.ErrInternalServerError
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	res := []dto.ListMerchantPromotionResponse{}
+	for _, promo := range promotions {
+		res = append(res, *promo.ToListMerchantPromotionResponse())
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: res, Meta: &dto.Meta{PaginationInfo: *pageInfo}})
+}
+
+func globalPromotionError(err error) shared.HTTPError {
+	var httpError shared.HTTPError
+	switch {
+	case errors.Is(err, usecase.ErrPromotionNotFound):
+		httpError = shared.ErrPromotionNotFound
+
+	case errors.Is(err, usecase.ErrNotGlobalPromotion):
+		httpError = shared.ErrForbiddenResource
+		httpError.Message = usecase.ErrNotGlobalPromotion.Error()
+
+	case errors.Is(err, usecase.ErrGlobalPromotionWithProducts),
+		errors.Is(err, usecase.ErrInvalidPromotionPeriod),
+		errors.Is(err, usecase.ErrInvalidAmountForDiscountTypePromo),
+		errors.Is(err, usecase.ErrInvalidAmountForShippingTypePromo),
+		errors.Is(err, usecase.ErrInvalidBundlePromotion):
+		httpError = shared.ErrBadRequest
+		httpError.Message = errors.Unwrap(err).Error()
+
+	case errors.Is(err, usecase.ErrInvalidMaxAmount):
+		httpError = shared.ErrBadRequest
+		httpError.Message = usecase.ErrInvalidMaxAmount.Error()
+
+	default:
+		httpError = shared.ErrInternalServerError
+	}
+
+	httpError.InternalError = err
+	return httpError
+}
+
+func (h *PromotionHandler) CreateFlashSale(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+	var req dto.ManageFlashSaleRequest
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if !user.IsSeller {
+		httpError = shared.ErrForbiddenResource
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	createFlashSaleDTO, err := dto.RequestToManageFlashSaleDTO(req)
+	if err != nil {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	if err := h.usecase.PromotionUsecase.CreateFlashSale(ctx, user, createFlashSaleDTO); err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrVariantNotFound):
+			httpError = shared.ErrVariantNotFound
+
+		case errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+
+		case errors.Is(err, repo.ErrFlashSaleOverlap):
+			httpError = shared.ErrFlashSaleOverlap
+
+		case errors.Is(err, usecase.ErrInvalidPromotionPeriod),
+			errors.Is(err, usecase.ErrInvalidFlashSalePrice),
+			errors.Is(err, usecase.ErrInvalidFlashSaleStock),
+			errors.Is(err, usecase.ErrDuplicateFlashSaleVariant):
+			httpError = shared.ErrBadRequest
+			httpError.Message = errors.Unwrap(err).Error()
+
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.JSONResponse{Message: "Successfully created flash sale"})
+}
+
+func (h *PromotionHandler) GetPromotionReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	var httpError shared.HTTPError
+
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		httpError = shared.ErrUnauthorizedAccess
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	promoID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || promoID <= 0 {
+		httpError = shared.ErrBadRequest
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	report, err := h.usecase.PromotionUsecase.GetPromotionReport(ctx, user, uint64(promoID))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPromotionNotFound):
+			httpError = shared.ErrPromotionNotFound
+
+		case errors.Is(err, usecase.ErrWrongUserTryingToAccessMerchant):
+			httpError = shared.ErrForbiddenResource
+
+		default:
+			httpError = shared.ErrInternalServerError
+		}
+
+		httpError.InternalError = err
+		_ = c.Error(&httpError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.JSONResponse{Data: report})
+}
@@ -54,6 +54,34 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+/* sets the user when a valid access token is sent, anonymous requests still go through */
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if os.Getenv("ENV_MODE") == "testing" {
+			c.Set("user_id", int64(1))
+			c.Next()
+			return
+		}
+
+		splittedHeader := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(splittedHeader) != 2 {
+			c.Next()
+			return
+		}
+
+		claims, err := auth.ValidateAccessToken(splittedHeader[1])
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Set("claims", claims)
+		c.Set("user", claims.User)
+
+		c.Next()
+	}
+}
+
 func RefreshTokenMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if os.Getenv("ENV_MODE") == "testing" {
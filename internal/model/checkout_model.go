@@ -20,6 +20,7 @@ type CheckoutProduct struct {
 	VariantChild                string          `gorm:"column:variant_child"`
 	Price                       decimal.Decimal `gorm:"column:price"`
 	Stock                       uint64          `gorm:"column:stock"`
+	Purchasable                 bool            `gorm:"column:purchasable"`
 }
 
 type Photos struct {
@@ -40,6 +41,17 @@ type PromotionProduct struct {
 	MaxAmount      *float64  `gorm:"column:max_amount"`
 }
 
+type BundlePromotionProduct struct {
+	PromotionId   uint64  `gorm:"column:promotion_id"`
+	PromoName     string  `gorm:"column:promo_name"`
+	PromotionType string  `gorm:"column:promotion_type"`
+	Amount        float64 `gorm:"column:amount"`
+	BuyQuantity   uint64  `gorm:"column:buy_quantity"`
+	GetQuantity   uint64  `gorm:"column:get_quantity"`
+	MerchantId    uint64  `gorm:"column:merchant_id"`
+	ProductId     uint64  `gorm:"column:product_id"`
+}
+
 type PromoName struct {
 	Id   uint64 `gorm:"column:promotion_id"`
 	Name string `gorm:"column:promo_name"`
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type FlashSale struct {
+	ID         uint64             `json:"id" gorm:"primarykey"`
+	MerchantID uint64             `gorm:"column:merchant_id"`
+	Name       string             `gorm:"column:name"`
+	StartDate  time.Time          `gorm:"column:start_date"`
+	EndDate    time.Time          `gorm:"column:end_date"`
+	Products   []FlashSaleProduct `gorm:"-"`
+	CreatedAt  time.Time          `gorm:"column:created_at;default:now()"`
+	UpdatedAt  time.Time          `gorm:"column:updated_at;default:now()"`
+	DeletedAt  *time.Time         `gorm:"column:deleted_at;default:null"`
+}
+
+type FlashSaleProduct struct {
+	ID                          uint64          `json:"id" gorm:"primarykey"`
+	FlashSaleID                 uint64          `gorm:"column:flash_sale_id"`
+	ProductID                   uint64          `gorm:"column:product_id"`
+	VariantCombinationProductID uint64          `gorm:"column:variant_combination_product_id"`
+	SalePrice                   decimal.Decimal `gorm:"column:sale_price"`
+	SaleStock                   uint64          `gorm:"column:sale_stock"`
+	Sold                        uint64          `gorm:"column:sold;default:0"`
+	PurchaseLimit               uint64          `gorm:"column:purchase_limit;default:0"`
+	CreatedAt                   time.Time       `gorm:"column:created_at;default:now()"`
+	UpdatedAt                   time.Time       `gorm:"column:updated_at;default:now()"`
+}
+
+type ActiveFlashSaleVariant struct {
+	FlashSaleProductID          uint64          `gorm:"column:flash_sale_product_id"`
+	ProductID                   uint64          `gorm:"column:product_id"`
+	VariantCombinationProductID uint64          `gorm:"column:variant_combination_product_id"`
+	SalePrice                   decimal.Decimal `gorm:"column:sale_price"`
+	SaleStock                   uint64          `gorm:"column:sale_stock"`
+	Sold                        uint64          `gorm:"column:sold"`
+	PurchaseLimit               uint64          `gorm:"column:purchase_limit"`
+	EndDate                     time.Time       `gorm:"column:end_date"`
+}
+
+func (v *ActiveFlashSaleVariant) RemainingStock() uint64 {
+	if v.Sold >= v.SaleStock {
+		return 0
+	}
+	return v.SaleStock - v.Sold
+}
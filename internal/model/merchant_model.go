@@ -17,3 +17,13 @@ type Merchant struct {
 	UpdatedAt   time.Time  `json:"updated_at" gorm:"column:updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
 }
+
+type MerchantRatingBreakdown struct {
+	ReviewAverage    float64          `json:"review_average"`
+	TotalReviews     int64            `json:"total_reviews"`
+	RatingCounts     map[uint64]int64 `json:"rating_counts" gorm:"-"`
+	DeliveredOrders  int64            `json:"delivered_orders"`
+	OnTimeRate       float64          `json:"on_time_rate"`
+	AverageShipHours float64          `json:"average_ship_hours"`
+	CanceledRate     float64          `json:"canceled_rate"`
+}
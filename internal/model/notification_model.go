@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type Notification struct {
+	ID        uint64     `json:"id" gorm:"primarykey"`
+	UserId    uint64     `json:"user_id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	ProductId *uint64    `json:"product_id" gorm:"default:null"`
+	ReadAt    *time.Time `json:"read_at" gorm:"default:null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
+}
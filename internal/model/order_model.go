@@ -19,21 +19,34 @@ type Orders struct {
 }
 
 type OrderDetails struct {
-	Id            uint64          `json:"id"`
-	OrderId       uint64          `json:"order_id"`
-	MerchantId    uint64          `json:"merchant_id"`
-	CourierId     string          `json:"courier_id"`
-	OrderStatus   string          `json:"order_status"`
-	EstimatedTime time.Time       `json:"estimated_time"`
-	Address       string          `gorm:"column:address"`
-	CourierPrice  decimal.Decimal `gorm:"column:courier_price"`
-	InitialPrice  decimal.Decimal `json:"initial_price"`
-	FinalPrice    decimal.Decimal `json:"final_price"`
-	Invoice       string          `gorm:"column:invoice"`
-	CreatedAt     time.Time       `json:"created_at" gorm:"default:now()"`
-	UpdatedAt     time.Time       `json:"updated_at" gorm:"default:now()"`
-	DeletedAt     *time.Time      `json:"-" gorm:"default:null"`
+	Id                  uint64                `json:"id"`
+	OrderId             uint64                `json:"order_id"`
+	MerchantId          uint64                `json:"merchant_id"`
+	CourierId           string                `json:"courier_id"`
+	OrderStatus         string                `json:"order_status"`
+	EstimatedTime       time.Time             `json:"estimated_time"`
+	Address             string                `gorm:"column:address"`
+	CourierPrice        decimal.Decimal       `gorm:"column:courier_price"`
+	InitialPrice        decimal.Decimal       `json:"initial_price"`
+	FinalPrice          decimal.Decimal       `json:"final_price"`
+	PlatformDiscount    decimal.Decimal       `gorm:"column:platform_discount"`
+	ShippingDiscount    decimal.Decimal       `gorm:"column:shipping_discount"`
+	Invoice             string                `gorm:"column:invoice"`
+	CreatedAt           time.Time             `json:"created_at" gorm:"default:now()"`
+	UpdatedAt           time.Time             `json:"updated_at" gorm:"default:now()"`
+	DeletedAt           *time.Time            `json:"-" gorm:"default:null"`
 	OrderDetailProducts []OrderDetailProducts `gorm:"-"`
+
+	/* scope of the free shipping voucher behind ShippingDiscount, only a global voucher is paid by the platform */
+	ShippingVoucherScope *string `json:"-" gorm:"default:null"`
+
+	/*
+		when the order left the merchant and reached the buyer, used to rate the shipping of the merchant. deliveries the
+		daily job confirmed on the estimated date are not a real arrival time and are left out of that rating
+	*/
+	ShippedAt             *time.Time `json:"shipped_at" gorm:"default:null"`
+	DeliveredAt           *time.Time `json:"delivered_at" gorm:"default:null"`
+	DeliveryAutoConfirmed bool       `json:"-" gorm:"default:false"`
 }
 
 type OrderDetailProducts struct {
@@ -47,6 +60,9 @@ type OrderDetailProducts struct {
 	Description                 string          `json:"description"`
 	Price                       decimal.Decimal `json:"price"`
 	MerchantId                  uint64          `json:"merchant_id"`
+	FlashSaleProductId          *uint64         `gorm:"column:flash_sale_product_id;default:null"`
+	BundlePromotionId           *uint64         `gorm:"column:bundle_promotion_id;default:null"`
+	BundleSaving                decimal.Decimal `gorm:"column:bundle_saving"`
 	CreatedAt                   time.Time       `json:"created_at" gorm:"default:now()"`
 	UpdatedAt                   time.Time       `json:"updated_at" gorm:"default:now()"`
 	DeletedAt                   *time.Time      `json:"-" gorm:"default:null"`
@@ -25,6 +25,8 @@ type Product struct {
 	AverageRating float64       `json:"average_rating" gorm:"default:0"`
 	TotalRating   uint64        `json:"total_rating" gorm:"default:0"`
 	TotalStock    uint64        `json:"total_stock" gorm:"default:0"`
+	ViewCount     uint64        `json:"view_count" gorm:"default:0"`
+	RecentViews   uint64        `json:"-" gorm:"column:recent_view_count;default:0"`
 	IsFavorite    bool          `json:"is_favorite" gorm:"-"`
 	IsActive      bool          `json:"is_active" gorm:"default:true"`
 	CategoryLv1Id string        `json:"category_lv1_id" binding:"required"`
@@ -34,24 +36,69 @@ type Product struct {
 	CreatedAt     time.Time     `json:"created_at" gorm:"default:now()"`
 	UpdatedAt     time.Time     `json:"updated_at" gorm:"default:now()"`
 	DeletedAt     *time.Time    `json:"deleted_at" gorm:"default:null"`
+
+	/* variants at or below the threshold are reported to the merchant, zero turns the alert off */
+	LowStockThreshold  uint64     `json:"low_stock_threshold" gorm:"default:0"`
+	AutoDeactivate     bool       `json:"auto_deactivate" gorm:"default:false"`
+	AutoDeactivatedAt  *time.Time `json:"-" gorm:"default:null"`
+	LowStockNotifiedAt *time.Time `json:"-" gorm:"default:null"`
+
+	/* drafts stay inactive until they are completed, a publish time keeps a completed product inactive until then */
+	IsDraft   bool       `json:"is_draft" gorm:"default:false"`
+	PublishAt *time.Time `json:"publish_at" gorm:"default:null"`
 }
 
 type ListProduct struct {
-	ID            uint64 `json:"id" gorm:"primarykey"`
-	MerchantId    uint64 `json:"merchant_id" binding:"required"`
-	Username      string
-	Title         string          `json:"title" binding:"required"`
-	Photo         string          `json:"photo" binding:"required"`
-	TotalSold     uint64          `json:"total_sold" gorm:"default:0"`
-	FavCount      uint64          `json:"favorite_count" gorm:"default:0"`
-	AverageRating float64         `json:"average_rating" gorm:"default:0"`
-	TotalStock    uint64          `json:"total_stock" gorm:"default:0"`
-	City          string          `json:"city"`
-	CategoryLv1Id string          `json:"category_lv1_id" binding:"required"`
-	CategoryLv2Id string          `json:"category_lv2_id"`
-	CategoryLv3Id string          `json:"category_lv3_id"`
-	MinPrice      decimal.Decimal `json:"min_price"`
-	IsActive      bool            `json:"is_active"`
-	CreatedAt     time.Time       `json:"created_at" gorm:"default:now()"`
-	UpdatedAt     time.Time       `json:"updated_at" gorm:"default:now()"`
+	ID             uint64 `json:"id" gorm:"primarykey"`
+	MerchantId     uint64 `json:"merchant_id" binding:"required"`
+	Username       string
+	Title          string           `json:"title" binding:"required"`
+	Photo          string           `json:"photo" binding:"required"`
+	TotalSold      uint64           `json:"total_sold" gorm:"default:0"`
+	FavCount       uint64           `json:"favorite_count" gorm:"default:0"`
+	AverageRating  float64          `json:"average_rating" gorm:"default:0"`
+	TotalStock     uint64           `json:"total_stock" gorm:"default:0"`
+	City           string           `json:"city"`
+	CategoryLv1Id  string           `json:"category_lv1_id" binding:"required"`
+	CategoryLv2Id  string           `json:"category_lv2_id"`
+	CategoryLv3Id  string           `json:"category_lv3_id"`
+	MinPrice       decimal.Decimal  `json:"min_price"`
+	FlashSalePrice *decimal.Decimal `json:"flash_sale_price"`
+	IsActive       bool             `json:"is_active"`
+	CreatedAt      time.Time        `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time        `json:"updated_at" gorm:"default:now()"`
+}
+
+type ProductSuggestion struct {
+	Products   []string
+	Categories []string
+	Merchants  []string
+}
+
+type ProductFacet struct {
+	Facet    string           `gorm:"column:facet"`
+	Value    string           `gorm:"column:value"`
+	Label    string           `gorm:"column:label"`
+	Count    uint64           `gorm:"column:count"`
+	MinValue *decimal.Decimal `gorm:"column:min_value"`
+	MaxValue *decimal.Decimal `gorm:"column:max_value"`
+}
+
+/* a variant whose stock is at or below the low stock threshold of its product, with the merchant to notify */
+type LowStockVariant struct {
+	ProductId         uint64
+	Title             string
+	LowStockThreshold uint64
+	UserId            uint64
+	Email             string
+	VariantName       string
+	Stock             uint64
+}
+
+/* a product whose visibility is changed by the stock job, with the merchant to notify */
+type StockChangedProduct struct {
+	ProductId uint64
+	Title     string
+	UserId    uint64
+	Email     string
 }
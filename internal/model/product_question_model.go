@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type ProductQuestion struct {
+	ID           uint64     `json:"id" gorm:"primarykey"`
+	ProductId    uint64     `json:"product_id"`
+	ProductTitle string     `json:"product_title,omitempty" gorm:"->;-:migration"`
+	UserId       uint64     `json:"-"`
+	UserName     string     `json:"user_name" gorm:"->;-:migration"`
+	Question     string     `json:"question"`
+	Answer       *string    `json:"answer" gorm:"default:null"`
+	AnsweredAt   *time.Time `json:"answered_at" gorm:"default:null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+type ProductQuestionCount struct {
+	Total    int64
+	Answered int64
+}
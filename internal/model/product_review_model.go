@@ -18,4 +18,32 @@ type ProductReview struct {
 	CreatedAt     time.Time    `json:"created_at" gorm:"default:now()"`
 	UpdatedAt     time.Time    `json:"updated_at" gorm:"default:now()"`
 	DeletedAt     sql.NullTime `json:"-" gorm:"default:null"`
+
+	/* one public reply of the merchant, it can be edited once shortly after it was posted */
+	Reply         *string    `json:"reply" gorm:"default:null"`
+	RepliedAt     *time.Time `json:"replied_at" gorm:"default:null"`
+	ReplyEditedAt *time.Time `json:"reply_edited_at" gorm:"default:null"`
+
+	HelpfulCount uint64 `json:"helpful_count" gorm:"default:0"`
+}
+
+/* one row per user and review, the pair is unique */
+type ReviewHelpfulVote struct {
+	ID              uint64    `json:"id" gorm:"primarykey"`
+	ProductReviewId uint64    `json:"product_review_id"`
+	UserId          uint64    `json:"user_id"`
+	CreatedAt       time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+type MerchantReview struct {
+	ID            uint64     `json:"id"`
+	ProductId     uint64     `json:"product_id"`
+	ProductTitle  string     `json:"product_title"`
+	UserName      string     `json:"user_name"`
+	Rating        uint64     `json:"rating"`
+	Description   string     `json:"comment"`
+	Reply         *string    `json:"reply"`
+	RepliedAt     *time.Time `json:"replied_at"`
+	ReplyEditedAt *time.Time `json:"reply_edited_at"`
+	CreatedAt     time.Time  `json:"created_at"`
 }
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+/* views of a product on one day, trending only sums the last few days of these */
+type ProductDailyView struct {
+	ProductId uint64    `json:"product_id" gorm:"primaryKey"`
+	ViewDate  time.Time `json:"view_date" gorm:"primaryKey;type:date"`
+	Views     uint64    `json:"views"`
+}
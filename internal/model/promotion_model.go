@@ -16,6 +16,8 @@ type Promotion struct {
 	Amount         float64
 	Quota          uint64
 	MaxAmount      *decimal.Decimal
+	BuyQuantity    uint64 `gorm:"default:0"`
+	GetQuantity    uint64 `gorm:"default:0"`
 	StartDate      time.Time
 	EndDate        time.Time
 	Products       []ListProduct `gorm:"-"`
@@ -33,3 +35,11 @@ type MerchantProductPromotion struct {
 	UpdatedAt   time.Time
 	DeletedAt   *time.Time `gorm:"default:null"`
 }
+
+type PromotionReport struct {
+	Date          time.Time       `gorm:"column:date"`
+	Redemptions   uint64          `gorm:"column:redemptions"`
+	TotalDiscount decimal.Decimal `gorm:"column:total_discount"`
+	GMV           decimal.Decimal `gorm:"column:gmv"`
+	UniqueBuyers  uint64          `gorm:"column:unique_buyers"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+/*
+one change of the stock of a variant, Quantity is negative when stock went down. ReferenceId is the order detail
+product of a sale or a cancel and is empty for changes made by the merchant
+*/
+type StockMovement struct {
+	ID                          uint64    `json:"id" gorm:"primarykey"`
+	VariantCombinationProductId uint64    `json:"variant_combination_product_id"`
+	ProductId                   uint64    `json:"product_id"`
+	VariantName                 string    `json:"variant_name" gorm:"->;-:migration"`
+	Quantity                    int64     `json:"quantity"`
+	StockAfter                  uint64    `json:"stock_after"`
+	Reason                      string    `json:"reason"`
+	ReferenceId                 *uint64   `json:"reference_id" gorm:"default:null"`
+	CreatedAt                   time.Time `json:"created_at" gorm:"default:now()"`
+}
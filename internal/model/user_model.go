@@ -14,6 +14,7 @@ type User struct {
 	DateOfBirth    time.Time `gorm:"column:date_of_birth"`
 	PhoneNumber    string     `gorm:"column:phone_number"`
 	IsSeller       bool       `gorm:"column:is_seller"`
+	IsAdmin        bool       `gorm:"column:is_admin;default:false"`
 	CreatedAt      time.Time  `gorm:"column:created_at"`
 	UpdatedAt      time.Time  `gorm:"column:updated_at"`
 	DeletedAt      *time.Time `gorm:"column:deleted_at"`
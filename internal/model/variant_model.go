@@ -35,32 +35,39 @@ type VariantGroup struct {
 }
 
 type VariantDetailResult struct {
-	VariantCombinationProductId uint64          `json:"variant_combination_product_id"`
-	TypeNameParent              string          `json:"type_name_parent"`
-	GroupNameParent             string          `json:"group_name_parent"`
-	TypeNameChild               string          `json:"type_name_child"`
-	GroupNameChild              string          `json:"group_name_child"`
-	Stock                       uint            `json:"stock"`
-	Price                       decimal.Decimal `json:"price"`
-	VariantImage                string          `json:"variant_image"`
+	VariantCombinationProductId uint64           `json:"variant_combination_product_id"`
+	TypeNameParent              string           `json:"type_name_parent"`
+	GroupNameParent             string           `json:"group_name_parent"`
+	TypeNameChild               string           `json:"type_name_child"`
+	GroupNameChild              string           `json:"group_name_child"`
+	Stock                       uint             `json:"stock"`
+	Price                       decimal.Decimal  `json:"price"`
+	VariantImage                string           `json:"variant_image"`
+	SalePrice                   *decimal.Decimal `json:"sale_price"`
+	SaleStock                   uint             `json:"sale_stock"`
+	SaleEndDate                 *time.Time       `json:"sale_end_date"`
 }
 
 type VariantParent struct {
-	VariantCombinationProductId uint64          `json:"variant_combination_product_id,omitempty"`
-	ParentName                  string          `json:"parent_name"`
-	ParentGroup                 string          `json:"parent_group"`
-	VariantChild                []VariantChild  `json:"variant_child,omitempty"`
-	Price                       decimal.Decimal `json:"price,omitempty" gorm:"type:decimal(64,2);default:null"`
-	Stock                       uint            `json:"stock,omitempty"`
-	ParentPicture               string          `json:"parent_picture,omitempty"`
+	VariantCombinationProductId uint64           `json:"variant_combination_product_id,omitempty"`
+	ParentName                  string           `json:"parent_name"`
+	ParentGroup                 string           `json:"parent_group"`
+	VariantChild                []VariantChild   `json:"variant_child,omitempty"`
+	Price                       decimal.Decimal  `json:"price,omitempty" gorm:"type:decimal(64,2);default:null"`
+	Stock                       uint             `json:"stock,omitempty"`
+	SalePrice                   *decimal.Decimal `json:"sale_price,omitempty"`
+	SaleStock                   uint             `json:"sale_stock,omitempty"`
+	ParentPicture               string           `json:"parent_picture,omitempty"`
 }
 
 type VariantChild struct {
-	VariantCombinationProductId uint64          `json:"variant_combination_product_id"`
-	ChildName                   string          `json:"child_name"`
-	ChildGroup                  string          `json:"child_group"`
-	Price                       decimal.Decimal `json:"price,omitempty"`
-	Stock                       uint            `json:"stock,omitempty"`
+	VariantCombinationProductId uint64           `json:"variant_combination_product_id"`
+	ChildName                   string           `json:"child_name"`
+	ChildGroup                  string           `json:"child_group"`
+	Price                       decimal.Decimal  `json:"price,omitempty"`
+	Stock                       uint             `json:"stock,omitempty"`
+	SalePrice                   *decimal.Decimal `json:"sale_price,omitempty"`
+	SaleStock                   uint             `json:"sale_stock,omitempty"`
 }
 
 type VariantCombinationDetailResult struct {
@@ -16,6 +16,7 @@ type checkoutRepo struct {
 	db              *gorm.DB
 	transactionRepo TransactionRepo
 	walletRepo      WalletRepo
+	flashSaleRepo   FlashSaleRepo
 }
 
 type CheckoutRepo interface {
@@ -23,10 +24,11 @@ type CheckoutRepo interface {
 	GetCheckoutDetails(c context.Context, cartId uint64) (map[uint64][]model.CheckoutProduct, error)
 	GetPromotionDetail(c context.Context, promoId uint64) (*model.PromotionProduct, error)
 	GetPromotion(ctx context.Context, merchantIds []uint64, productIds []uint64) ([]model.PromoName, error)
+	GetBundlePromotions(ctx context.Context, productIds []uint64) ([]model.BundlePromotionProduct, error)
 }
 
-func NewCheckoutRepo(db *gorm.DB, trx TransactionRepo, w WalletRepo) CheckoutRepo {
-	return &checkoutRepo{db: db, transactionRepo: trx, walletRepo: w}
+func NewCheckoutRepo(db *gorm.DB, trx TransactionRepo, w WalletRepo, fs FlashSaleRepo) CheckoutRepo {
+	return &checkoutRepo{db: db, transactionRepo: trx, walletRepo: w, flashSaleRepo: fs}
 }
 
 func (cr *checkoutRepo) GetPromotion(ctx context.Context, merchantIds []uint64, productIds []uint64) ([]model.PromoName, error) {
@@ -36,8 +38,10 @@ func (cr *checkoutRepo) GetPromotion(ctx context.Context, merchantIds []uint64,
 	SELECT p.id AS promotion_id, p.promo_name AS promo_name FROM promotions p 
 	LEFT JOIN merchant_product_promotions mpp ON p.id  = mpp.promotion_id 
 	WHERE start_date < NOW() AND end_date > now() 
+	AND p.promotion_type NOT IN ?
 	AND ((mpp.product_id in ? OR (mpp.merchant_id in ? AND mpp.product_id IS NULL)) OR 
-	(mpp.product_id IS NULL AND mpp.merchant_id IS NULL AND promotion_scope = 'GLOBAL'))`, productIds, merchantIds).
+	(mpp.product_id IS NULL AND mpp.merchant_id IS NULL AND promotion_scope = 'GLOBAL'))`,
+		[]string{shared.BuyXGetY.String(), shared.Bundle.String()}, productIds, merchantIds).
 		Scan(&promoNames).Error
 
 	if err != nil {
@@ -46,6 +50,35 @@ func (cr *checkoutRepo) GetPromotion(ctx context.Context, merchantIds []uint64,
 	return promoNames, nil
 }
 
+func (cr *checkoutRepo) GetBundlePromotions(ctx context.Context, productIds []uint64) ([]model.BundlePromotionProduct, error) {
+	bundles := []model.BundlePromotionProduct{}
+	if len(productIds) == 0 {
+		return bundles, nil
+	}
+
+	err := cr.db.WithContext(ctx).Raw(`SELECT
+	p.id AS promotion_id,
+	p.promo_name AS promo_name,
+	p.promotion_type AS promotion_type,
+	p.amount AS amount,
+	p.buy_quantity AS buy_quantity,
+	p.get_quantity AS get_quantity,
+	mpp.merchant_id AS merchant_id,
+	mpp.product_id AS product_id
+FROM
+	promotions p INNER JOIN merchant_product_promotions mpp
+	ON p.id = mpp.promotion_id
+WHERE p.promotion_type IN ?
+	AND p.deleted_at IS NULL
+	AND p.start_date < NOW() AND p.end_date > NOW()
+	AND p.id IN (SELECT promotion_id FROM merchant_product_promotions WHERE product_id IN ?)
+ORDER BY p.id`, []string{shared.BuyXGetY.String(), shared.Bundle.String()}, productIds).Scan(&bundles).Error
+	if err != nil {
+		return nil, fmt.Errorf("checkoutRepo/GetBundlePromotions %w", err)
+	}
+	return bundles, nil
+}
+
 func (cr *checkoutRepo) GetPromotionDetail(c context.Context, promoId uint64) (*model.PromotionProduct, error) {
 	var promotionDetail model.PromotionProduct
 
@@ -84,7 +117,8 @@ func (cr *checkoutRepo) GetCheckoutDetails(c context.Context, cartId uint64) (ma
 	vtp.type_name AS variant_parrent,
 	vtc.type_name AS variant_child,
 	vcp.price AS price,
-	vcp.stock AS stock	
+	vcp.stock AS stock,
+	(`+purchasableProductCondition+`) AS purchasable
 FROM 
 	cart_products cp INNER JOIN variant_combination_products vcp ON cp.variant_combination_product_id = vcp.id
 	INNER JOIN products p ON vcp.product_id = p.id 
@@ -97,6 +131,12 @@ FROM
 		return nil, fmt.Errorf("checkoutRepo/GetCheckoutDetails %w", err)
 	}
 
+	for _, product := range checkoutProduct {
+		if !product.Purchasable {
+			return nil, fmt.Errorf("checkoutRepo/GetCheckoutDetails %w", ErrVariantNotFound)
+		}
+	}
+
 	for _, product := range checkoutProduct {
 		image := []model.Photos{}
 		err := cr.db.WithContext(c).Raw(`SELECT url, is_default 
@@ -139,10 +179,16 @@ func (c *checkoutRepo) CreateOrder(ctx context.Context, orderDto *dto.Orders, ph
 			if err != nil {
 				return fmt.Errorf("checkoutRepo/CreateOrder : %w", err)
 			}
-			c.decreaseProductStock(tx, orderDetailProductModel.VariantCombinationProductId, orderDetailProductModel.Quantity)
+			err = c.decreaseProductStock(tx, orderDetailProductModel)
 			if err != nil {
 				return fmt.Errorf("checkoutRepo/CreateOrder : %w", err)
 			}
+			if orderDetailProductModel.FlashSaleProductId != nil {
+				err = c.flashSaleRepo.IncreaseFlashSaleSold(ctx, tx, *orderDetailProductModel.FlashSaleProductId, orderDetailProductModel.Quantity)
+				if err != nil {
+					return fmt.Errorf("checkoutRepo/CreateOrder : %w", err)
+				}
+			}
 			err = c.createProductPhotosOrder(tx, orderDetailProductModel.Id, photos[orderDetailProduct.ProductId])
 			if err != nil {
 				return fmt.Errorf("checkoutRepo/CreateOrder : %w", err)
@@ -157,8 +203,8 @@ func (c *checkoutRepo) CreateOrder(ctx context.Context, orderDto *dto.Orders, ph
 	return nil
 }
 
-func (cr *checkoutRepo) decreaseProductStock(tx *gorm.DB, variantCombinationId uint64, stock uint64) error {
-	err := tx.Table("variant_combination_products").Where("id = ?", variantCombinationId).Update("stock", gorm.Expr("stock - ?", stock)).Error
+func (cr *checkoutRepo) decreaseProductStock(tx *gorm.DB, orderDetailProduct model.OrderDetailProducts) error {
+	err := moveVariantStock(tx, orderDetailProduct.VariantCombinationProductId, -int64(orderDetailProduct.Quantity), shared.StockSale, &orderDetailProduct.Id)
 	if err != nil {
 		return fmt.Errorf("checkoutRepo/decreaseProductStock : %w", err)
 	}
@@ -187,11 +233,13 @@ func (c *checkoutRepo) createPayment(ctx context.Context, tx *gorm.DB, order mod
 		return fmt.Errorf("error checkoutRepo/createPayment: %w", err)
 	}
 	desc := fmt.Sprintf("Payment for order %d", order.Id)
+	/* platform discount is covered by the admin wallet, buyer only pays the rest */
+	paidPrice := order.FinalPrice.Sub(order.PlatformDiscount)
 	transactionOut := &model.Transaction{
 		WalletId:    *walletID,
 		SenderId:    walletID,
 		RecipientId: walletModel.WalletId,
-		Amount:      order.FinalPrice.Neg(),
+		Amount:      paidPrice.Neg(),
 		Description: &desc,
 	}
 	payment := &model.Payment{
@@ -206,7 +254,7 @@ func (c *checkoutRepo) createPayment(ctx context.Context, tx *gorm.DB, order mod
 		WalletId:    walletModel.WalletId,
 		SenderId:    walletID,
 		RecipientId: walletModel.WalletId,
-		Amount:      order.FinalPrice,
+		Amount:      paidPrice,
 		Description: &desc,
 	}
 	err = c.transactionRepo.CreateTransaction(ctx, tx, transactionIn)
@@ -214,11 +262,13 @@ func (c *checkoutRepo) createPayment(ctx context.Context, tx *gorm.DB, order mod
 		return fmt.Errorf("error checkoutRepo/createPayment: %w", err)
 	}
 	desc = fmt.Sprintf("Payment for courier %s", order.CourierId)
+	/* the courier is still paid in full, DistributeOrder charges the discount to whoever funds the voucher */
+	paidCourierPrice := order.CourierPrice.Sub(order.ShippingDiscount)
 	transactionCourierOut := &model.Transaction{
 		WalletId:    *walletID,
 		SenderId:    walletID,
 		RecipientId: walletModel.WalletId,
-		Amount:      order.CourierPrice.Neg(),
+		Amount:      paidCourierPrice.Neg(),
 		Description: &desc,
 	}
 	err = c.transactionRepo.CreateTransaction(ctx, tx, transactionCourierOut)
@@ -229,7 +279,7 @@ func (c *checkoutRepo) createPayment(ctx context.Context, tx *gorm.DB, order mod
 		WalletId:    walletModel.WalletId,
 		SenderId:    walletID,
 		RecipientId: walletModel.WalletId,
-		Amount:      order.CourierPrice.Neg(),
+		Amount:      paidCourierPrice,
 		Description: &desc,
 	}
 	err = c.transactionRepo.CreateTransaction(ctx, tx, transactionCourierIn)
@@ -251,16 +301,20 @@ func (c *checkoutRepo) extractOrder(orderDto *dto.Orders) model.Orders {
 
 func (c *checkoutRepo) extractOrderDetail(orderDto dto.OrderDetails) model.OrderDetails {
 	return model.OrderDetails{
-		OrderId:       orderDto.OrderId,
-		MerchantId:    orderDto.MerchantId,
-		CourierId:     orderDto.CourierId,
-		OrderStatus:   orderDto.OrderStatus,
-		EstimatedTime: orderDto.EstimatedTime,
-		Address:       orderDto.Address,
-		InitialPrice:  orderDto.InitialPrice,
-		FinalPrice:    orderDto.FinalPrice,
-		Invoice:       orderDto.Invoice,
-		CourierPrice:  orderDto.CourierPrice,
+		OrderId:          orderDto.OrderId,
+		MerchantId:       orderDto.MerchantId,
+		CourierId:        orderDto.CourierId,
+		OrderStatus:      orderDto.OrderStatus,
+		EstimatedTime:    orderDto.EstimatedTime,
+		Address:          orderDto.Address,
+		InitialPrice:     orderDto.InitialPrice,
+		FinalPrice:       orderDto.FinalPrice,
+		PlatformDiscount: orderDto.PlatformDiscount,
+		ShippingDiscount: orderDto.ShippingDiscount,
+		Invoice:          orderDto.Invoice,
+		CourierPrice:     orderDto.CourierPrice,
+
+		ShippingVoucherScope: orderDto.ShippingVoucherScope,
 	}
 }
 
@@ -275,6 +329,9 @@ func (c *checkoutRepo) extractOrderDetailProduct(orderDto dto.OrderDetailProduct
 		Description:                 orderDto.Description,
 		Price:                       orderDto.Price,
 		MerchantId:                  orderDto.MerchantId,
+		FlashSaleProductId:          orderDto.FlashSaleProductId,
+		BundlePromotionId:           orderDto.BundlePromotionId,
+		BundleSaving:                orderDto.BundleSaving,
 	}
 }
 
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+/* position of the last row of a page, SortKey is the value of the sort column and ID breaks ties */
+type pageCursor struct {
+	SortKey string `json:"k"`
+	ID      uint64 `json:"id"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageCursor(token string) (*pageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+/* keyset condition for the next page, rows come after the cursor in the listing order */
+func keysetCondition(sortKey string, id string, sortOrder string) string {
+	if sortOrder == "ASC" {
+		return "(" + sortKey + ", " + id + ") > (?, ?)"
+	}
+	return "(" + sortKey + ", " + id + ") < (?, ?)"
+}
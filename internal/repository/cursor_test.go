@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDecodePageCursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  *pageCursor
+		err   error
+	}{
+		{
+			name:  "encoded cursor comes back as it was",
+			token: pageCursor{SortKey: "2026-10-19T08:00:00Z", ID: 42}.encode(),
+			want:  &pageCursor{SortKey: "2026-10-19T08:00:00Z", ID: 42},
+		},
+		{
+			name:  "numeric sort key",
+			token: pageCursor{SortKey: "4.75", ID: 7}.encode(),
+			want:  &pageCursor{SortKey: "4.75", ID: 7},
+		},
+		{
+			name:  "not base64",
+			token: "%%%",
+			err:   ErrInvalidCursor,
+		},
+		{
+			name:  "not json",
+			token: base64.RawURLEncoding.EncodeToString([]byte("page 2")),
+			err:   ErrInvalidCursor,
+		},
+		{
+			name:  "missing id",
+			token: base64.RawURLEncoding.EncodeToString([]byte(`{"k":"10"}`)),
+			err:   ErrInvalidCursor,
+		},
+		{
+			name:  "padded base64 is refused",
+			token: base64.URLEncoding.EncodeToString([]byte(`{"k":"10","id":1}`)),
+			err:   ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePageCursor(tt.token)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("cursor = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortKey   string
+		id        string
+		sortOrder string
+		want      string
+	}{
+		{
+			name:      "ascending pages continue after the cursor",
+			sortKey:   "mp.min_price",
+			id:        "p.id",
+			sortOrder: "ASC",
+			want:      "(mp.min_price, p.id) > (?, ?)",
+		},
+		{
+			name:      "descending pages continue below the cursor",
+			sortKey:   "p.total_sold",
+			id:        "p.id",
+			sortOrder: "DESC",
+			want:      "(p.total_sold, p.id) < (?, ?)",
+		},
+		{
+			name:      "anything but ASC is descending",
+			sortKey:   "o.created_at",
+			id:        "o.id",
+			sortOrder: "",
+			want:      "(o.created_at, o.id) < (?, ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keysetCondition(tt.sortKey, tt.id, tt.sortOrder); got != tt.want {
+				t.Errorf("condition = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
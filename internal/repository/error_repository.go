@@ -22,4 +22,16 @@ var (
 	ErrWalletNotFound        = errors.New("wallet not found")
 	ErrOrderDetailNotFound   = errors.New(shared.ErrOrderDetailNotFound.Message)
 	ErrOrderNotFound         = errors.New(shared.ErrOrderNotFound.Message)
+	ErrPromotionNotFound     = errors.New(shared.ErrPromotionNotFound.Message)
+	ErrFlashSaleSoldOut      = errors.New(shared.ErrFlashSaleSoldOut.Message)
+	ErrFlashSaleOverlap      = errors.New(shared.ErrFlashSaleOverlap.Message)
+	ErrInvalidCursor         = errors.New(shared.ErrInvalidCursor.Message)
+	ErrVariantNotFound       = errors.New(shared.ErrVariantNotFound.Message)
+	ErrVariantNotOwned       = errors.New("product variant belongs to another merchant")
+	ErrNotificationNotFound  = errors.New(shared.ErrNotificationNotFound.Message)
+	ErrProductNotArchived    = errors.New(shared.ErrProductNotArchived.Message)
+	ErrQuestionNotFound      = errors.New(shared.ErrQuestionNotFound.Message)
+	ErrReviewNotFound        = errors.New(shared.ErrReviewNotFound.Message)
+	ErrReviewReplied         = errors.New(shared.ErrReviewReplied.Message)
+	ErrReplyNotEditable      = errors.New(shared.ErrReplyNotEditable.Message)
 )
@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"digital-test-vm/be/internal/shared"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type FlashSaleRepo interface {
+	CreateFlashSale(ctx context.Context, flashSale *model.FlashSale) error
+	GetActiveFlashSaleVariants(ctx context.Context, tx *gorm.DB, variantCombinationProductIDs []uint64) (map[uint64]model.ActiveFlashSaleVariant, error)
+	CountUserFlashSalePurchases(ctx context.Context, tx *gorm.DB, userID uint64, flashSaleProductID uint64) (uint64, error)
+	IncreaseFlashSaleSold(ctx context.Context, tx *gorm.DB, flashSaleProductID uint64, quantity uint64) error
+}
+
+type flashSaleRepo struct {
+	db *gorm.DB
+}
+
+func NewFlashSaleRepo(db *gorm.DB) FlashSaleRepo {
+	return &flashSaleRepo{
+		db: db,
+	}
+}
+
+/* flash sale products that are in their time window and still have sale stock */
+var activeFlashSaleProductQuery = `SELECT
+	fsp.id AS flash_sale_product_id, fsp.product_id, fsp.variant_combination_product_id,
+	fsp.sale_price, fsp.sale_stock, fsp.sold, fsp.purchase_limit, fs.end_date
+	FROM flash_sale_products fsp
+	INNER JOIN flash_sales fs
+		ON fs.id = fsp.flash_sale_id
+		AND fs.deleted_at IS NULL
+		AND fs.start_date <= NOW()
+		AND fs.end_date >= NOW()
+	WHERE fsp.sold < fsp.sale_stock`
+
+func (r *flashSaleRepo) CreateFlashSale(ctx context.Context, flashSale *model.FlashSale) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, p := range flashSale.Products {
+			var overlapping int64
+			err := tx.Table("flash_sale_products fsp").
+				Joins("INNER JOIN flash_sales fs ON fs.id = fsp.flash_sale_id AND fs.deleted_at IS NULL").
+				Where("fsp.variant_combination_product_id = ? AND fs.start_date <= ? AND fs.end_date >= ?", p.VariantCombinationProductID, flashSale.EndDate, flashSale.StartDate).
+				Count(&overlapping).Error
+			if err != nil {
+				return err
+			}
+			if overlapping > 0 {
+				return ErrFlashSaleOverlap
+			}
+		}
+
+		if err := tx.Omit("Products").Create(flashSale).Error; err != nil {
+			return err
+		}
+		for i := range flashSale.Products {
+			flashSale.Products[i].FlashSaleID = flashSale.ID
+			if err := tx.Create(&flashSale.Products[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("flashSaleRepo/CreateFlashSale: %w", err)
+	}
+	return nil
+}
+
+func (r *flashSaleRepo) GetActiveFlashSaleVariants(ctx context.Context, tx *gorm.DB, variantCombinationProductIDs []uint64) (map[uint64]model.ActiveFlashSaleVariant, error) {
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+
+	res := make(map[uint64]model.ActiveFlashSaleVariant)
+	if len(variantCombinationProductIDs) == 0 {
+		return res, nil
+	}
+
+	variants := []model.ActiveFlashSaleVariant{}
+	q := activeFlashSaleProductQuery + " AND fsp.variant_combination_product_id IN ?"
+	if err := db.WithContext(ctx).Raw(q, variantCombinationProductIDs).Scan(&variants).Error; err != nil {
+		return nil, fmt.Errorf("flashSaleRepo/GetActiveFlashSaleVariants: %w", err)
+	}
+	for _, v := range variants {
+		res[v.VariantCombinationProductID] = v
+	}
+	return res, nil
+}
+
+func (r *flashSaleRepo) CountUserFlashSalePurchases(ctx context.Context, tx *gorm.DB, userID uint64, flashSaleProductID uint64) (uint64, error) {
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+
+	var total uint64
+	err := db.WithContext(ctx).Raw(`SELECT COALESCE(SUM(odp.quantity), 0)
+		FROM order_detail_products odp
+		INNER JOIN order_details od
+			ON od.id = odp.order_detail_id
+		INNER JOIN orders o
+			ON o.id = od.order_id
+		INNER JOIN carts c
+			ON c.id = o.cart_id
+		WHERE c.user_id = ?
+		AND odp.flash_sale_product_id = ?
+		AND od.order_status <> ?`, userID, flashSaleProductID, shared.Canceled.String()).Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("flashSaleRepo/CountUserFlashSalePurchases: %w", err)
+	}
+	return total, nil
+}
+
+func (r *flashSaleRepo) IncreaseFlashSaleSold(ctx context.Context, tx *gorm.DB, flashSaleProductID uint64, quantity uint64) error {
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+
+	/* guarded update so concurrent checkouts cannot oversell the sale stock */
+	q := db.WithContext(ctx).Model(&model.FlashSaleProduct{}).
+		Where("id = ? AND sold + ? <= sale_stock", flashSaleProductID, quantity).
+		Update("sold", gorm.Expr("sold + ?", quantity))
+	if q.Error != nil {
+		return fmt.Errorf("flashSaleRepo/IncreaseFlashSaleSold: %w", q.Error)
+	}
+	if q.RowsAffected == 0 {
+		return fmt.Errorf("flashSaleRepo/IncreaseFlashSaleSold: %w", ErrFlashSaleSoldOut)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"digital-test-vm/be/internal/shared"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/* where uploaded files are kept, Put returns the public url of the stored file */
+type MediaStorage interface {
+	Put(ctx context.Context, key string, contentType string, body []byte) (string, error)
+}
+
+func NewMediaStorage(cfg *shared.MediaConfig) MediaStorage {
+	if cfg.Driver == shared.MediaDriverS3 {
+		return &s3MediaStorage{
+			endpoint:  cfg.S3Endpoint,
+			region:    cfg.S3Region,
+			bucket:    cfg.S3Bucket,
+			accessKey: cfg.S3AccessKey,
+			secretKey: cfg.S3SecretKey,
+			baseURL:   cfg.BaseURL,
+			client:    &http.Client{Timeout: 30 * time.Second},
+		}
+	}
+	return &localMediaStorage{
+		dir:     cfg.LocalDir,
+		baseURL: cfg.BaseURL,
+	}
+}
+
+type localMediaStorage struct {
+	dir     string
+	baseURL string
+}
+
+func (s *localMediaStorage) Put(ctx context.Context, key string, contentType string, body []byte) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("localMediaStorage/Put: %w", err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return "", fmt.Errorf("localMediaStorage/Put: %w", err)
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+/* path style PutObject signed with aws signature v4, works against s3 and s3 compatible servers such as minio */
+type s3MediaStorage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+func (s *s3MediaStorage) Put(ctx context.Context, key string, contentType string, body []byte) (string, error) {
+	endpoint, err := url.Parse(s.endpoint)
+	if err != nil {
+		return "", fmt.Errorf("s3MediaStorage/Put: %w", err)
+	}
+	objectPath := "/" + s.bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.endpoint+objectPath, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("s3MediaStorage/Put: %w", err)
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		http.MethodPut,
+		objectPath,
+		"",
+		"content-type:" + contentType,
+		"host:" + endpoint.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("s3MediaStorage/Put: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", fmt.Errorf("s3MediaStorage/Put: status %d: %s", res.StatusCode, msg)
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
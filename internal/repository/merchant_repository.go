@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"digital-test-vm/be/internal/model"
+	"digital-test-vm/be/internal/shared"
 	"errors"
 	"fmt"
 
@@ -24,6 +25,9 @@ type MerchantRepo interface {
 	CreateMerchant(ctx context.Context, merchant *model.Merchant) (*model.Merchant, error)
 	FindMerchantByUserID(ctx context.Context, userId uint64) (*model.Merchant, error)
 	GetMerchantByUserId(c context.Context, userId uint64) (*model.Merchant, error)
+
+	GetMerchantRatingBreakdown(c context.Context, merchantId uint64) (*model.MerchantRatingBreakdown, error)
+	RefreshMerchantRatings(c context.Context) error
 }
 
 func NewMerchantRepo(db *gorm.DB) MerchantRepo {
@@ -118,3 +122,83 @@ func (r *merchantRepo) GetMerchantByUserId(c context.Context, userId uint64) (*m
 	}
 	return &result, nil
 }
+
+/* share of the merchant rating that comes from reviews, the rest comes from delivering on time */
+const merchantReviewWeight = 0.8
+
+/*
+rating of the merchants matched by where: the average review when no delivery was confirmed yet, otherwise the average
+review blended with the on time rate of deliveries confirmed by the courier scaled to five stars. merchants without
+reviews are rated on shipping alone, and a merchant with neither keeps its rating
+*/
+func refreshMerchantRating(tx *gorm.DB, where string, args ...interface{}) error {
+	query := `UPDATE merchants m
+		SET rating = COALESCE((
+			SELECT CASE
+				WHEN r.average IS NULL THEN s.on_time * 5
+				WHEN s.on_time IS NULL THEN r.average
+				ELSE r.average * ? + s.on_time * ?
+			END
+			FROM (
+				SELECT AVG(pr.rating) AS average
+				FROM product_reviews pr
+				INNER JOIN products p ON p.id = pr.product_id
+				WHERE p.merchant_id = m.id AND pr.deleted_at IS NULL
+			) r, (
+				SELECT AVG(CASE WHEN od.delivered_at::date <= od.estimated_time::date THEN 1.0 ELSE 0.0 END) AS on_time
+				FROM order_details od
+				WHERE od.merchant_id = m.id AND od.delivered_at IS NOT NULL AND od.delivery_auto_confirmed IS FALSE AND od.deleted_at IS NULL
+			) s
+		), m.rating)
+		WHERE ` + where
+	args = append([]interface{}{merchantReviewWeight, (1 - merchantReviewWeight) * 5}, args...)
+	return tx.Exec(query, args...).Error
+}
+
+/* shipping keeps changing without new reviews, so every merchant is recounted by the nightly job */
+func (r *merchantRepo) RefreshMerchantRatings(c context.Context) error {
+	if err := refreshMerchantRating(r.db.WithContext(c), "m.deleted_at IS NULL"); err != nil {
+		return fmt.Errorf("merchantRepo/RefreshMerchantRatings: %w", err)
+	}
+	return nil
+}
+
+func (r *merchantRepo) GetMerchantRatingBreakdown(c context.Context, merchantId uint64) (*model.MerchantRatingBreakdown, error) {
+	res := model.MerchantRatingBreakdown{}
+	err := r.db.WithContext(c).Raw(`SELECT
+		COUNT(*) FILTER (WHERE delivered_at IS NOT NULL AND delivery_auto_confirmed IS FALSE) AS delivered_orders,
+		COALESCE(AVG(CASE WHEN delivered_at::date <= estimated_time::date THEN 1.0 ELSE 0.0 END)
+			FILTER (WHERE delivered_at IS NOT NULL AND delivery_auto_confirmed IS FALSE), 0) AS on_time_rate,
+		COALESCE(AVG(EXTRACT(EPOCH FROM shipped_at - created_at) / 3600) FILTER (WHERE shipped_at IS NOT NULL), 0) AS average_ship_hours,
+		COALESCE(AVG(CASE WHEN order_status = ? THEN 1.0 ELSE 0.0 END), 0) AS canceled_rate
+		FROM order_details
+		WHERE merchant_id = ? AND deleted_at IS NULL`, shared.Canceled.String(), merchantId).Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("merchantRepo/GetMerchantRatingBreakdown: %w", err)
+	}
+
+	counts := []struct {
+		Rating uint64
+		Total  int64
+	}{}
+	err = r.db.WithContext(c).Raw(`SELECT pr.rating, COUNT(*) AS total
+		FROM product_reviews pr
+		INNER JOIN products p ON p.id = pr.product_id
+		WHERE p.merchant_id = ? AND pr.deleted_at IS NULL
+		GROUP BY pr.rating`, merchantId).Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("merchantRepo/GetMerchantRatingBreakdown: %w", err)
+	}
+
+	res.RatingCounts = map[uint64]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	var sum int64
+	for _, count := range counts {
+		res.RatingCounts[count.Rating] = count.Total
+		res.TotalReviews += count.Total
+		sum += int64(count.Rating) * count.Total
+	}
+	if res.TotalReviews > 0 {
+		res.ReviewAverage = float64(sum) / float64(res.TotalReviews)
+	}
+	return &res, nil
+}
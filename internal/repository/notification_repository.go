@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notificationRepo struct {
+	db *gorm.DB
+}
+
+type NotificationRepo interface {
+	CreateNotifications(c context.Context, notifications []model.Notification) error
+	GetNotifications(c context.Context, userId uint64, cursor string, limit int, unreadOnly bool) ([]model.Notification, string, error)
+	CountUnreadNotifications(c context.Context, userId uint64) (int64, error)
+	MarkNotificationRead(c context.Context, userId uint64, notificationId uint64) error
+	MarkAllNotificationsRead(c context.Context, userId uint64) error
+}
+
+func NewNotificationRepo(db *gorm.DB) NotificationRepo {
+	return &notificationRepo{db: db}
+}
+
+func (r *notificationRepo) CreateNotifications(c context.Context, notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(c).Create(&notifications).Error; err != nil {
+		return fmt.Errorf("notificationRepo/CreateNotifications: %w", err)
+	}
+	return nil
+}
+
+/* newest first, ids only grow so the id of the last notification is enough to find the next page */
+func (r *notificationRepo) GetNotifications(c context.Context, userId uint64, cursor string, limit int, unreadOnly bool) ([]model.Notification, string, error) {
+	res := []model.Notification{}
+	q := r.db.WithContext(c).Model(&model.Notification{}).Where("user_id = ?", userId)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if cursor != "" {
+		pc, err := decodePageCursor(cursor)
+		if err != nil {
+			return []model.Notification{}, "", fmt.Errorf("notificationRepo/GetNotifications: %w", err)
+		}
+		q = q.Where("id < ?", pc.ID)
+	}
+	if err := q.Order("id DESC").Limit(limit + 1).Find(&res).Error; err != nil {
+		return []model.Notification{}, "", fmt.Errorf("notificationRepo/GetNotifications: %w", err)
+	}
+
+	nextCursor := ""
+	if len(res) > limit {
+		res = res[:limit]
+		nextCursor = pageCursor{ID: res[len(res)-1].ID}.encode()
+	}
+	return res, nextCursor, nil
+}
+
+func (r *notificationRepo) CountUnreadNotifications(c context.Context, userId uint64) (int64, error) {
+	var total int64
+	err := r.db.WithContext(c).Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("notificationRepo/CountUnreadNotifications: %w", err)
+	}
+	return total, nil
+}
+
+func (r *notificationRepo) MarkNotificationRead(c context.Context, userId uint64, notificationId uint64) error {
+	var notification model.Notification
+	err := r.db.WithContext(c).Where("id = ? AND user_id = ?", notificationId, userId).First(&notification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("notificationRepo/MarkNotificationRead: %w", ErrNotificationNotFound)
+		}
+		return fmt.Errorf("notificationRepo/MarkNotificationRead: %w", err)
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	if err := r.db.WithContext(c).Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("notificationRepo/MarkNotificationRead: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepo) MarkAllNotificationsRead(c context.Context, userId uint64) error {
+	err := r.db.WithContext(c).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("notificationRepo/MarkAllNotificationsRead: %w", err)
+	}
+	return nil
+}
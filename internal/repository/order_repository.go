@@ -5,8 +5,11 @@ import (
 	"digital-test-vm/be/internal/dto"
 	"digital-test-vm/be/internal/model"
 	"digital-test-vm/be/internal/shared"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -20,13 +23,13 @@ type OrderRepo interface {
 	CheckOrderDetailStatus(c context.Context, orderDetailProductId uint64) (string, error)
 	GetListTransactionByOrderId(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListTransaction, error)
 	GetListTransactionById(c context.Context, orderId uint64) ([]dto.ListTransaction, error)
-	GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, error)
+	GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, string, error)
 	GetOrderDetailById(c context.Context, orderDetailId uint64) (*model.OrderDetails, uint64, error)
 	// GetListTransaction(c context.Context, req dto.ListTransactionRequest, userId uint64) ([]dto.ListTransactionResponse, error)
 	ChangeOrderStatus(c context.Context, orderDetailId uint64, status string) error
 	GetOrderById(c context.Context, id uint64) (*model.Orders, error)
 	GetOrderDetailsProduct(ctx context.Context, orderDetailId, userId uint64) ([]dto.ListTransactionProduct, error)
-	GetPaginationInfoTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, error)
+	GetPaginationInfoTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) (uint64, error)
 	DistributeOrder(ctx context.Context, order model.OrderDetails, walletAdmin *string, walletMerchant *string, walletCourier *string) error
 	GetBuyerByOrderId(c context.Context, orderDetailId uint64) (*dto.SellerOrderBuyerInformation, error)
 	GetListSellerOrderByOrderId(c context.Context, orderDetailId uint64) ([]dto.ListSellerOrder, error)
@@ -34,6 +37,8 @@ type OrderRepo interface {
 	GetPaginationListSeller(c context.Context, req dto.ListSellerTransactionRequest, merchantId uint64) ([]dto.ListSellerOrderId, error)
 	IsOrderDetailExists(c context.Context, orderDetailId uint64) error
 	GetAllOrderWithStatusOnDelivery(c context.Context) ([]model.OrderDetails, error)
+	AutoConfirmDelivery(c context.Context, orderDetailId uint64) error
+	CancelOrderDetail(c context.Context, order model.OrderDetails, walletAdmin *string, walletBuyer *string) error
 }
 
 func NewOrderRepo(db *gorm.DB, trx TransactionRepo, productReviewRepo ProductReviewRepo) OrderRepo {
@@ -71,12 +76,13 @@ func (c *orderRepo) DistributeOrder(ctx context.Context, order model.OrderDetail
 	tx := c.db.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
+	payout := merchantPayout(order)
 	desc := fmt.Sprintf("Payment for merchant %d", order.MerchantId)
 	transactionOut := &model.Transaction{
 		WalletId:    *walletAdmin,
 		SenderId:    walletAdmin,
 		RecipientId: *walletMerchant,
-		Amount:      order.FinalPrice.Neg(),
+		Amount:      payout.Neg(),
 		Description: &desc,
 	}
 	err := c.transactionRepo.CreateTransaction(ctx, tx, transactionOut)
@@ -88,7 +94,7 @@ func (c *orderRepo) DistributeOrder(ctx context.Context, order model.OrderDetail
 		WalletId:    *walletMerchant,
 		SenderId:    walletAdmin,
 		RecipientId: *walletMerchant,
-		Amount:      order.FinalPrice,
+		Amount:      payout,
 		Description: &desc,
 	}
 	err = c.transactionRepo.CreateTransaction(ctx, tx, transactionIn)
@@ -111,7 +117,7 @@ func (c *orderRepo) DistributeOrder(ctx context.Context, order model.OrderDetail
 		WalletId:    *walletCourier,
 		SenderId:    walletAdmin,
 		RecipientId: *walletCourier,
-		Amount:      order.FinalPrice,
+		Amount:      order.CourierPrice,
 		Description: &desc,
 	}
 	err = c.transactionRepo.CreateTransaction(ctx, tx, transactionCourierIn)
@@ -122,6 +128,17 @@ func (c *orderRepo) DistributeOrder(ctx context.Context, order model.OrderDetail
 	return nil
 }
 
+/*
+the courier is always paid the full price from the escrow, so a free shipping voucher the merchant created is taken
+out of the merchant payout while a global voucher stays with the platform like the platform discount
+*/
+func merchantPayout(order model.OrderDetails) decimal.Decimal {
+	if order.ShippingVoucherScope == nil || *order.ShippingVoucherScope == shared.GlobalScope.String() {
+		return order.FinalPrice
+	}
+	return order.FinalPrice.Sub(order.ShippingDiscount)
+}
+
 func (r *orderRepo) CheckOrderDetailStatus(c context.Context, orderDetailId uint64) (string, error) {
 	var res dto.OrderStatus
 	if err := r.db.WithContext(c).Raw(`select order_status FROM order_details od
@@ -170,38 +187,126 @@ func (r *orderRepo) GetListTransactionById(c context.Context, orderId uint64) ([
 	return res, nil
 }
 
-func (r *orderRepo) GetPaginationInfoTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, error) {
-	var res []dto.ListOrder
-	q := r.db.WithContext(c).Table(`orders as o`).Select(`o.id as order_id, o.final_price`).Where(`o.cart_id=?`, cartId)
+func (r *orderRepo) GetPaginationInfoTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) (uint64, error) {
+	var total int64
+	q := r.db.WithContext(c).Table(`orders as o`).Where(`o.cart_id=?`, cartId)
 	if req.Status != "" {
-		q.Where(`od.order_status=?`, req.Status)
+		q = q.Where(`EXISTS (SELECT 1 FROM order_details od WHERE od.order_id = o.id AND od.order_status = ?)`, req.Status)
 	}
-	if err := q.Scan(&res).Error; err != nil {
-		return nil, ErrInternalServerError
+	if err := q.Count(&total).Error; err != nil {
+		return 0, ErrInternalServerError
 	}
-	return res, nil
+	return uint64(total), nil
 }
 
-func (r *orderRepo) GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, error) {
+func (r *orderRepo) GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) ([]dto.ListOrder, string, error) {
 	var res []dto.ListOrder
-	q := r.db.WithContext(c).Table(`orders as o`).Select(`o.id as order_id, o.final_price as order_price`).Where(`o.cart_id=?`, cartId)
+	q := r.db.WithContext(c).Table(`orders as o`).Select(`o.id as order_id, o.final_price as order_price, o.created_at`).Where(`o.cart_id=?`, cartId)
 	if req.Status != "" {
-		q.Where(`od.order_status=?`, req.Status)
+		q = q.Where(`EXISTS (SELECT 1 FROM order_details od WHERE od.order_id = o.id AND od.order_status = ?)`, req.Status)
 	}
-	q.Limit(10).Offset(10 * (int(req.Page) - 1)).Order("o.created_at desc")
+	if req.Cursor != "" {
+		cursor, err := decodePageCursor(req.Cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("orderRepo/GetPaginationListTransaction %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, cursor.SortKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("orderRepo/GetPaginationListTransaction %w", ErrInvalidCursor)
+		}
+		q = q.Where(keysetCondition("o.created_at", "o.id", shared.DESC.String()), createdAt, cursor.ID)
+	} else {
+		q = q.Offset(10 * (int(req.Page) - 1))
+	}
+	q = q.Limit(10 + 1).Order("o.created_at desc, o.id desc")
 	if err := q.Scan(&res).Error; err != nil {
-		return nil, ErrInternalServerError
+		return nil, "", ErrInternalServerError
 	}
-	return res, nil
+
+	nextCursor := ""
+	if len(res) > 10 {
+		res = res[:10]
+		last := res[len(res)-1]
+		nextCursor = pageCursor{SortKey: last.CreatedAt.Format(time.RFC3339Nano), ID: last.OrderId}.encode()
+	}
+	return res, nextCursor, nil
 }
 
 func (r *orderRepo) ChangeOrderStatus(c context.Context, orderDetailId uint64, status string) error {
-	if err := r.db.WithContext(c).Model(&model.OrderDetails{}).Where("id=?", orderDetailId).Update("order_status", status).Error; err != nil {
+	update := map[string]interface{}{"order_status": status}
+	switch status {
+	case shared.OnDelivery.String():
+		update["shipped_at"] = gorm.Expr("NOW()")
+	case shared.Delivered.String():
+		update["delivered_at"] = gorm.Expr("NOW()")
+	}
+	if err := r.db.WithContext(c).Model(&model.OrderDetails{}).Where("id=?", orderDetailId).Updates(update).Error; err != nil {
 		return fmt.Errorf("orderRepo/ChangeOrderStatus %w", ErrInternalServerError)
 	}
 	return nil
 }
 
+/*
+an order can only be canceled while it waits for the seller, its stock and flash sale quota go back and the buyer
+gets back from the escrow exactly what createPayment took, all in one transaction
+*/
+func (r *orderRepo) CancelOrderDetail(c context.Context, order model.OrderDetails, walletAdmin *string, walletBuyer *string) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.OrderDetails{}).Where("id = ? AND order_status = ?", order.Id, shared.WaitingForSeller.String()).
+			Update("order_status", shared.Canceled.String())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUnauthorizedAccess
+		}
+
+		products := []model.OrderDetailProducts{}
+		if err := tx.Where("order_detail_id = ? AND deleted_at IS NULL", order.Id).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, odp := range products {
+			if err := moveVariantStock(tx, odp.VariantCombinationProductId, int64(odp.Quantity), shared.StockCancel, &odp.Id); err != nil {
+				return err
+			}
+			if odp.FlashSaleProductId == nil {
+				continue
+			}
+			if err := tx.Exec("UPDATE flash_sale_products SET sold = GREATEST(sold - ?, 0) WHERE id = ?", odp.Quantity, *odp.FlashSaleProductId).Error; err != nil {
+				return err
+			}
+		}
+
+		refund := order.FinalPrice.Sub(order.PlatformDiscount).Add(order.CourierPrice).Sub(order.ShippingDiscount)
+		desc := fmt.Sprintf("Refund for order %d", order.Id)
+		transactionOut := &model.Transaction{
+			WalletId:    *walletAdmin,
+			SenderId:    walletAdmin,
+			RecipientId: *walletBuyer,
+			Amount:      refund.Neg(),
+			Description: &desc,
+		}
+		if err := r.transactionRepo.CreateTransaction(c, tx, transactionOut); err != nil {
+			return err
+		}
+		transactionIn := &model.Transaction{
+			WalletId:    *walletBuyer,
+			SenderId:    walletAdmin,
+			RecipientId: *walletBuyer,
+			Amount:      refund,
+			Description: &desc,
+		}
+		return r.transactionRepo.CreateTransaction(c, tx, transactionIn)
+	})
+	if errors.Is(err, ErrUnauthorizedAccess) {
+		return fmt.Errorf("orderRepo/CancelOrderDetail %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("orderRepo/CancelOrderDetail %w", ErrInternalServerError)
+	}
+	return nil
+}
+
 func (r *orderRepo) GetOrderById(c context.Context, id uint64) (*model.Orders, error) {
 	var res *model.Orders
 	if err := r.db.WithContext(c).Model(&model.Orders{}).Where(`id=?`, id).First(&res).Error; err != nil {
@@ -271,3 +376,18 @@ func (r *orderRepo) GetAllOrderWithStatusOnDelivery(c context.Context) ([]model.
 	}
 	return res, nil
 }
+
+/* an order still on delivery on its estimated date is taken as delivered without a confirmation of the courier */
+func (r *orderRepo) AutoConfirmDelivery(c context.Context, orderDetailId uint64) error {
+	err := r.db.WithContext(c).Model(&model.OrderDetails{}).
+		Where("id = ? AND order_status = ?", orderDetailId, shared.OnDelivery.String()).
+		Updates(map[string]interface{}{
+			"order_status":            shared.Delivered.String(),
+			"delivered_at":            gorm.Expr("NOW()"),
+			"delivery_auto_confirmed": true,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("orderRepo/AutoConfirmDelivery %w", ErrInternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/shared"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+/* conditions of a product listing, values are always bound as parameters and never spliced into the query */
+type productFilter struct {
+	conds []string
+	args  []interface{}
+}
+
+func (f *productFilter) and(cond string, args ...interface{}) *productFilter {
+	f.conds = append(f.conds, cond)
+	f.args = append(f.args, args...)
+	return f
+}
+
+/* wildcards typed by the user are matched literally, backslash is the default escape of ILIKE */
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (f *productFilter) titleContains(keyword string) *productFilter {
+	if keyword == "" {
+		return f
+	}
+	return f.and("p.title ILIKE ?", "%"+likeEscaper.Replace(keyword)+"%")
+}
+
+func (f *productFilter) search(keyword string) *productFilter {
+	if keyword == "" {
+		return f
+	}
+	return f.and(productSearchCondition, keyword, keyword)
+}
+
+/* the level of a category is part of its id, ids of unknown levels are ignored */
+func (f *productFilter) category(category string) *productFilter {
+	switch {
+	case strings.Contains(category, "LV-1"):
+		return f.and("p.category_lv1_id = ?", category)
+	case strings.Contains(category, "LV-2"):
+		return f.and("p.category_lv2_id = ?", category)
+	case strings.Contains(category, "LV-3"):
+		return f.and("p.category_lv3_id = ?", category)
+	}
+	return f
+}
+
+func (f *productFilter) priceBetween(min decimal.Decimal, max decimal.Decimal) *productFilter {
+	if !min.Equal(decimal.Zero) {
+		f.and("mp.min_price >= ?", min)
+	}
+	if !max.Equal(decimal.Zero) {
+		f.and("mp.min_price <= ?", max)
+	}
+	return f
+}
+
+func (f *productFilter) locations(districtCodes []uint64) *productFilter {
+	if len(districtCodes) == 0 {
+		return f
+	}
+	return f.and("a.district_code IN ?", districtCodes)
+}
+
+func (f *productFilter) inStock(only bool) *productFilter {
+	if !only {
+		return f
+	}
+	return f.and("p.total_stock > 0")
+}
+
+func (f *productFilter) activeOnly(only bool) *productFilter {
+	if !only {
+		return f
+	}
+	return f.and("p.is_active IS TRUE")
+}
+
+/* drafts and deleted products are only shown to their merchant */
+func (f *productFilter) published() *productFilter {
+	return f.and("p.is_draft IS FALSE AND p.deleted_at IS NULL")
+}
+
+/* a publish time is cleared once the product goes live, listings that also show inactive products still hide it until then */
+func (f *productFilter) released() *productFilter {
+	return f.and("p.publish_at IS NULL")
+}
+
+/* a product is on promotion when it is in a running flash sale or a running promotion targets it or its merchant */
+var productHasPromotionCondition = `(fsp.flash_sale_price IS NOT NULL OR EXISTS (
+	SELECT 1 FROM promotions pr
+	INNER JOIN merchant_product_promotions mpp
+		ON mpp.promotion_id = pr.id
+		AND mpp.deleted_at IS NULL
+	WHERE pr.deleted_at IS NULL
+		AND pr.start_date <= NOW() AND pr.end_date >= NOW()
+		AND (mpp.product_id = p.id OR (mpp.product_id IS NULL AND mpp.merchant_id = p.merchant_id))))`
+
+/* filters every product listing understands */
+func (f *productFilter) attributes(args dto.ProductFilterQueries) *productFilter {
+	if args.IsUsed != nil {
+		f.and("p.is_used = ?", *args.IsUsed)
+	}
+	if args.HasPromotion {
+		f.and(productHasPromotionCondition)
+	}
+	if len(args.CourierIDs) > 0 {
+		f.and("EXISTS (SELECT 1 FROM product_couriers pc WHERE pc.product_id = p.id AND pc.courier_id IN ?)", args.CourierIDs)
+	}
+	return f
+}
+
+func (f *productFilter) build() (string, []interface{}) {
+	if len(f.conds) == 0 {
+		return "", []interface{}{}
+	}
+	return " WHERE " + strings.Join(f.conds, " AND "), f.args
+}
+
+/* sort columns of the product listing as numbers so they can be carried in a page cursor, unknown sorts fall back to total sold */
+func productSortColumn(sortBy string) string {
+	switch sortBy {
+	case shared.Date.Translate():
+		return "EXTRACT(EPOCH FROM p.created_at)"
+	case shared.Price.Translate():
+		return "mp.min_price"
+	case shared.Rating.Translate():
+		return "p.average_rating"
+	case shared.Trending.Translate():
+		return "p.recent_view_count"
+	default:
+		return "p.total_sold"
+	}
+}
+
+func productSortOrder(sortOrder string) string {
+	if sortOrder == shared.ASC.String() {
+		return shared.ASC.String()
+	}
+	return shared.DESC.String()
+}
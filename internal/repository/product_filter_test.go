@@ -0,0 +1,29 @@
+package repo
+
+import "testing"
+
+func TestTitleContains(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyword string
+		pattern string
+	}{
+		{name: "plain keyword", keyword: "shirt", pattern: "%shirt%"},
+		{name: "percent is literal", keyword: "50%", pattern: `%50\%%`},
+		{name: "underscore is literal", keyword: "t_shirt", pattern: `%t\_shirt%`},
+		{name: "backslash is literal", keyword: `a\b`, pattern: `%a\\b%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := (&productFilter{}).titleContains(tt.keyword)
+			if len(f.args) != 1 || f.args[0] != tt.pattern {
+				t.Errorf("args = %v, want %s", f.args, tt.pattern)
+			}
+		})
+	}
+
+	if f := (&productFilter{}).titleContains(""); len(f.conds) != 0 {
+		t.Errorf("empty keyword added %v", f.conds)
+	}
+}
@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type productQuestionRepo struct {
+	db *gorm.DB
+}
+
+type ProductQuestionRepo interface {
+	CreateQuestion(c context.Context, question *model.ProductQuestion) error
+	GetProductQuestions(c context.Context, req dto.ListProductQuestionRequest) ([]model.ProductQuestion, string, error)
+	CountProductQuestions(c context.Context, productId uint64) (model.ProductQuestionCount, error)
+	GetQuestion(c context.Context, id uint64) (model.ProductQuestion, error)
+	AnswerQuestion(c context.Context, id uint64, answer string) error
+	GetUnansweredQuestions(c context.Context, merchantId uint64, cursor string, limit int) ([]model.ProductQuestion, string, error)
+}
+
+func NewProductQuestionRepo(db *gorm.DB) ProductQuestionRepo {
+	return &productQuestionRepo{db: db}
+}
+
+func (r *productQuestionRepo) CreateQuestion(c context.Context, question *model.ProductQuestion) error {
+	if err := r.db.WithContext(c).Create(question).Error; err != nil {
+		return fmt.Errorf("productQuestionRepo/CreateQuestion: %w", err)
+	}
+	return nil
+}
+
+/* newest first, ids only grow so the id of the last question is enough to find the next page */
+func (r *productQuestionRepo) GetProductQuestions(c context.Context, req dto.ListProductQuestionRequest) ([]model.ProductQuestion, string, error) {
+	res := []model.ProductQuestion{}
+	q := r.db.WithContext(c).Table("product_questions pq").
+		Select("pq.*, u.first_name AS user_name").
+		Joins("INNER JOIN users u ON u.id = pq.user_id").
+		Where("pq.product_id = ?", req.ProductId)
+	if req.AnsweredOnly {
+		q = q.Where("pq.answer IS NOT NULL")
+	}
+	if req.Cursor != "" {
+		cursor, err := decodePageCursor(req.Cursor)
+		if err != nil {
+			return []model.ProductQuestion{}, "", fmt.Errorf("productQuestionRepo/GetProductQuestions: %w", err)
+		}
+		q = q.Where("pq.id < ?", cursor.ID)
+	}
+	if err := q.Order("pq.id DESC").Limit(req.Limit + 1).Scan(&res).Error; err != nil {
+		return []model.ProductQuestion{}, "", fmt.Errorf("productQuestionRepo/GetProductQuestions: %w", err)
+	}
+
+	nextCursor := ""
+	if len(res) > req.Limit {
+		res = res[:req.Limit]
+		nextCursor = pageCursor{ID: res[len(res)-1].ID}.encode()
+	}
+	return res, nextCursor, nil
+}
+
+func (r *productQuestionRepo) CountProductQuestions(c context.Context, productId uint64) (model.ProductQuestionCount, error) {
+	res := model.ProductQuestionCount{}
+	err := r.db.WithContext(c).Model(&model.ProductQuestion{}).
+		Select("COUNT(*) AS total, COUNT(answer) AS answered").
+		Where("product_id = ?", productId).Scan(&res).Error
+	if err != nil {
+		return model.ProductQuestionCount{}, fmt.Errorf("productQuestionRepo/CountProductQuestions: %w", err)
+	}
+	return res, nil
+}
+
+func (r *productQuestionRepo) GetQuestion(c context.Context, id uint64) (model.ProductQuestion, error) {
+	res := model.ProductQuestion{}
+	if err := r.db.WithContext(c).Where("id = ?", id).First(&res).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ProductQuestion{}, fmt.Errorf("productQuestionRepo/GetQuestion: %w", ErrQuestionNotFound)
+		}
+		return model.ProductQuestion{}, fmt.Errorf("productQuestionRepo/GetQuestion: %w", err)
+	}
+	return res, nil
+}
+
+/* answering again replaces the previous answer */
+func (r *productQuestionRepo) AnswerQuestion(c context.Context, id uint64, answer string) error {
+	err := r.db.WithContext(c).Model(&model.ProductQuestion{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"answer":      answer,
+		"answered_at": gorm.Expr("NOW()"),
+		"updated_at":  gorm.Expr("NOW()"),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("productQuestionRepo/AnswerQuestion: %w", err)
+	}
+	return nil
+}
+
+/* oldest first so the merchant works through the questions that waited longest */
+func (r *productQuestionRepo) GetUnansweredQuestions(c context.Context, merchantId uint64, cursor string, limit int) ([]model.ProductQuestion, string, error) {
+	res := []model.ProductQuestion{}
+	q := r.db.WithContext(c).Table("product_questions pq").
+		Select("pq.*, p.title AS product_title, u.first_name AS user_name").
+		Joins("INNER JOIN products p ON p.id = pq.product_id").
+		Joins("INNER JOIN users u ON u.id = pq.user_id").
+		Where("p.merchant_id = ? AND p.deleted_at IS NULL AND pq.answer IS NULL", merchantId)
+	if cursor != "" {
+		pc, err := decodePageCursor(cursor)
+		if err != nil {
+			return []model.ProductQuestion{}, "", fmt.Errorf("productQuestionRepo/GetUnansweredQuestions: %w", err)
+		}
+		q = q.Where("pq.id > ?", pc.ID)
+	}
+	if err := q.Order("pq.id ASC").Limit(limit + 1).Scan(&res).Error; err != nil {
+		return []model.ProductQuestion{}, "", fmt.Errorf("productQuestionRepo/GetUnansweredQuestions: %w", err)
+	}
+
+	nextCursor := ""
+	if len(res) > limit {
+		res = res[:limit]
+		nextCursor = pageCursor{ID: res[len(res)-1].ID}.encode()
+	}
+	return res, nextCursor, nil
+}
@@ -7,7 +7,6 @@ import (
 	"digital-test-vm/be/internal/shared"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -30,11 +29,12 @@ type productRepo struct {
 }
 
 type ProductRepo interface {
-	ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, error)
-	ListProducts(ctx context.Context, args dto.ListProductQueries) ([]model.ListProduct, uint64, error)
+	ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, string, error)
+	ListProducts(ctx context.Context, args dto.ListProductQueries) ([]model.ListProduct, uint64, string, error)
+	ListProductFacets(ctx context.Context, args dto.ListProductQueries) ([]model.ProductFacet, error)
 	GetDetailProduct(c context.Context, productId uint64) (model.Product, error)
 	IsProductExist(c context.Context, id uint64) (model.Product, error)
-	ListProductsByUserID(ctx context.Context, userID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, error)
+	ListProductsByUserID(ctx context.Context, userID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, string, error)
 	DeactivateProduct(c context.Context, productId uint64) error
 	ActivateProduct(c context.Context, productId uint64) error
 	GetProductFavorite(c context.Context, productId uint64) (*[]model.ListProduct, error)
@@ -43,20 +43,36 @@ type ProductRepo interface {
 	GetProductByVariantCombinationProductId(ctx context.Context, variantCombinationProductId uint64) (*model.Product, error)
 	UpdateProduct(ctx context.Context, merchantID uint64, updateProductDTO *dto.ManageProduct) error
 	SingleListProductByID(ctx context.Context, tx *gorm.DB, productID uint64) (*model.ListProduct, error)
+	GetProductIDsByMerchantID(ctx context.Context, merchantID uint64) ([]uint64, error)
+	GetDraftProducts(ctx context.Context, merchantID uint64) ([]model.Product, error)
+	PublishScheduledProducts(ctx context.Context) ([]uint64, error)
+	DeleteProduct(ctx context.Context, productID uint64) error
+	RestoreProduct(ctx context.Context, productID uint64) error
+	GetArchivedProducts(ctx context.Context, merchantID uint64) ([]model.Product, error)
 }
 
 func NewProductRepo(db *gorm.DB, merchantRepo MerchantRepo, categoryRepo CategoryRepo, productFavoriteRepo ProductFavoriteRepo, variantRepo VariantRepo) ProductRepo {
 	return &productRepo{db: db, merchantRepo: merchantRepo, categoryRepo: categoryRepo, productFavoriteRepo: productFavoriteRepo, variantRepo: variantRepo}
 }
 
-var listProductBaseQuery = `SELECT
+var listProductColumns = `
 	p.id, u.username, p.merchant_id, pp.url AS photo, p.title, p.total_sold,
-	p.fav_count, p.average_rating, p.total_stock, a.district AS city,
-	p.category_lv1_id, p.category_lv2_id, p.category_Lv3_id, mp.min_price, p.is_active, p.created_at, p.updated_at
+	p.fav_count, p.average_rating, p.total_stock, a.district AS city, a.district_code,
+	p.category_lv1_id, p.category_lv2_id, p.category_Lv3_id, mp.min_price, fsp.flash_sale_price, p.is_active, p.created_at, p.updated_at`
+
+var listProductBaseQuery = `SELECT` + listProductColumns + listProductFromQuery
+
+var listProductFromQuery = `
 	FROM products p
 	INNER JOIN
 		(SELECT product_id, MIN(price) AS min_price FROM variant_combination_products GROUP BY product_id) AS mp
 		ON mp.product_id = p.id
+	LEFT JOIN
+		(SELECT fsp.product_id, MIN(fsp.sale_price) AS flash_sale_price FROM flash_sale_products fsp
+			INNER JOIN flash_sales fs ON fs.id = fsp.flash_sale_id AND fs.deleted_at IS NULL
+			AND fs.start_date <= NOW() AND fs.end_date >= NOW()
+			WHERE fsp.sold < fsp.sale_stock GROUP BY fsp.product_id) AS fsp
+		ON fsp.product_id = p.id
 	INNER JOIN merchants m
 		ON m.id = p.merchant_id
 	INNER JOIN users u
@@ -68,176 +84,236 @@ var listProductBaseQuery = `SELECT
 		ON p.id = pp.product_id
 		AND pp.is_default IS TRUE`
 
-func (r *productRepo) ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, error) {
-	products := []model.ListProduct{}
-
-	baseQ := listProductBaseQuery + " WHERE merchant_id = ?"
-
-	if args.Keyword != "" {
-		baseQ += " AND title ILIKE " + "'%" + args.Keyword + "%'"
-	}
-
-	if args.Category != "" {
-		baseQ += " AND category_lv1_id = " + "'" + args.Category + "'"
-	}
-
-	if args.ExcludeNoStock {
-		baseQ += " AND p.total_stock > 0 "
+/*
+p.search_document is maintained by the triggers in internal/database/sql/product_search.sql,
+<% is the pg_trgm word similarity operator so both sides of the OR are served by an index
+*/
+var productSearchCondition = "(p.search_document @@ plainto_tsquery('simple', ?) OR ? <% p.title)"
+
+var productSearchRank = "(ts_rank(p.search_document, plainto_tsquery('simple', ?)) + word_similarity(?, p.title))"
+
+func (r *productRepo) ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, string, error) {
+	f := &productFilter{}
+	f.and("p.merchant_id = ?", merchantID).
+		published().
+		released().
+		titleContains(args.Keyword).
+		category(args.Category).
+		inStock(args.ExcludeNoStock).
+		activeOnly(args.ExcludeNotActive).
+		attributes(args.ProductFilterQueries)
+	where, whereArgs := f.build()
+
+	products, totalItems, nextCursor, err := pageListProducts(ctx, r.db, listProductPage{
+		sortKey:   productSortColumn(args.SortBy),
+		where:     where,
+		whereArgs: whereArgs,
+		sortOrder: args.SortOrder,
+		cursor:    args.Cursor,
+		page:      args.Page,
+		limit:     args.Limit,
+	})
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("productRepo/ListProductsByMerchantID: %w", err)
 	}
 
-	if args.ExcludeNotActive {
-		baseQ += " AND p.is_active = TRUE"
-	}
+	return products, totalItems, nextCursor, nil
+}
 
-	additionalSort := ""
-	if args.SortBy != "created_at" {
-		additionalSort = ", created_at DESC"
+func (r *productRepo) ListProductsByUserID(ctx context.Context, userID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, string, error) {
+	f := &productFilter{}
+	f.and("m.user_id = ?", userID).
+		published().
+		titleContains(args.Keyword).
+		category(args.Category).
+		inStock(args.ExcludeNoStock).
+		activeOnly(args.ExcludeNotActive).
+		attributes(args.ProductFilterQueries)
+	where, whereArgs := f.build()
+
+	products, totalItems, nextCursor, err := pageListProducts(ctx, r.db, listProductPage{
+		sortKey:   productSortColumn(args.SortBy),
+		where:     where,
+		whereArgs: whereArgs,
+		sortOrder: args.SortOrder,
+		cursor:    args.Cursor,
+		page:      args.Page,
+		limit:     args.Limit,
+	})
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("productRepo/ListProductsByUserID: %w", err)
 	}
-	qForCount := baseQ
-	baseQ += " ORDER BY " + args.SortBy + " " + args.SortOrder + additionalSort
 
-	offset := (args.Page - 1) * args.Limit
-	baseQ += " LIMIT ? OFFSET ? "
-
-	query := r.db.WithContext(ctx).Table("products").Raw(
-		baseQ, merchantID, args.Limit, offset)
+	return products, totalItems, nextCursor, nil
+}
 
-	var totalItems int64
-	if err := r.db.WithContext(ctx).Table("products").Raw(qForCount, true, 0, merchantID).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProductsByMerchantID: %w", err)
-	}
-	totalItems = int64(len(products))
+/* filters shared by the product listing and its facets, only active products in stock are listed */
+func listProductsFilter(args dto.ListProductQueries) (string, []interface{}) {
+	f := &productFilter{}
+	f.activeOnly(true).
+		published().
+		inStock(true).
+		and("p.average_rating >= ?", args.MinRating).
+		priceBetween(args.MinPrice, args.MaxPrice).
+		search(args.Keyword).
+		category(args.Category).
+		locations(args.Locations).
+		attributes(args.ProductFilterQueries)
+	return f.build()
+}
 
-	if err := query.Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProductsByMerchantID: %w", err)
+func (r *productRepo) ListProducts(ctx context.Context, args dto.ListProductQueries) ([]model.ListProduct, uint64, string, error) {
+	args.Keyword = strings.TrimSpace(args.Keyword)
+	where, whereArgs := listProductsFilter(args)
+
+	page := listProductPage{
+		sortKey:   productSortColumn(args.SortBy),
+		where:     where,
+		whereArgs: whereArgs,
+		sortOrder: args.SortOrder,
+		cursor:    args.Cursor,
+		page:      args.Page,
+		limit:     args.Limit,
+	}
+	switch {
+	case args.SortBy == shared.Relevance.Translate() && args.Keyword != "":
+		page.sortKey = productSearchRank
+		page.sortArgs = []interface{}{args.Keyword, args.Keyword}
+		page.sortOrder = shared.DESC.String()
+	case args.SortBy == shared.Recommended.Translate() || args.SortBy == shared.Relevance.Translate():
+		page.withQ, page.withArgs, page.sortKey = recommendationScore(args.ViewerID)
+		page.sortOrder = shared.DESC.String()
+	}
+
+	products, totalItems, nextCursor, err := pageListProducts(ctx, r.db, page)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("productRepo/ListProducts: %w", err)
 	}
 
-	return products, uint64(totalItems), nil
+	return products, totalItems, nextCursor, nil
 }
 
+type listProductPage struct {
+	withQ     string
+	withArgs  []interface{}
+	sortKey   string
+	sortArgs  []interface{}
+	where     string
+	whereArgs []interface{}
+	sortOrder string
+	cursor    string
+	page      uint64
+	limit     uint64
+}
 
+type listProductRow struct {
+	model.ListProduct
+	SortKey decimal.Decimal `gorm:"column:sort_key"`
+}
 
-func (r *productRepo) ListProductsByUserID(ctx context.Context, userID uint64, args dto.ListProductByMerchantQueries) ([]model.ListProduct, uint64, error) {
+/*
+one page of the product listing. with a cursor the page starts right after it (keyset),
+without one the page number is used. the total is counted in the database
+*/
+func pageListProducts(ctx context.Context, db *gorm.DB, page listProductPage) ([]model.ListProduct, uint64, string, error) {
 	products := []model.ListProduct{}
 
-	baseQ := listProductBaseQuery + " WHERE m.user_id = ?"
-
-	if args.Keyword != "" {
-		baseQ += " AND title ILIKE " + "'%" + args.Keyword + "%'"
-	}
-
-	if strings.Contains(args.Category, "LV-1") {
-		baseQ += " AND category_lv1_id = " + "'" + args.Category + "'"
-	}
-	if strings.Contains(args.Category, "LV-2") {
-		baseQ += " AND category_lv2_id = " + "'" + args.Category + "'"
-	}
-	if strings.Contains(args.Category, "LV-3") {
-		baseQ += " AND category_lv3_id = " + "'" + args.Category + "'"
-	}
-
-	if args.ExcludeNoStock {
-		baseQ += " AND p.total_stock > 0 "
-	}
-
-	if args.ExcludeNotActive {
-		baseQ += " AND p.is_active IS TRUE"
-	}
-
-	additionalSort := ""
-	if args.SortBy != "created_at" {
-		additionalSort = ", created_at DESC"
-	}
-	qForCount := baseQ
-	baseQ += " ORDER BY " + args.SortBy + " " + args.SortOrder + additionalSort
-
-	offset := (args.Page - 1) * args.Limit
-	baseQ += " LIMIT ? OFFSET ? "
-
-	query := r.db.WithContext(ctx).Table("products").Raw(
-		baseQ, userID, args.Limit, offset)
-
 	var totalItems int64
-	if err := r.db.WithContext(ctx).Table("products").Raw(qForCount, userID).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProductsByUserID: %w", err)
-	}
-	totalItems = int64(len(products))
-
-	if err := query.Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProductsByUserID: %w", err)
+	countQ := page.withQ + "SELECT COUNT(*) FROM (" + listProductBaseQuery + page.where + ") AS listing"
+	countArgs := append(append([]interface{}{}, page.withArgs...), page.whereArgs...)
+	if err := db.WithContext(ctx).Raw(countQ, countArgs...).Scan(&totalItems).Error; err != nil {
+		return nil, 0, "", err
 	}
 
-	return products, uint64(totalItems), nil
-}
-
-func (r *productRepo) ListProducts(ctx context.Context, args dto.ListProductQueries) ([]model.ListProduct, uint64, error) {
-	products := []model.ListProduct{}
-
-	baseQ := listProductBaseQuery + " WHERE p.is_active = TRUE AND p.total_stock > 0 AND average_rating >= ? "
-
-	if !args.MinPrice.Equal(decimal.Zero) {
-		baseQ += " AND min_price >= " + args.MinPrice.String()
-	}
+	sortOrder := productSortOrder(page.sortOrder)
 
-	if !args.MaxPrice.Equal(decimal.Zero) {
-		baseQ += " AND min_price <= " + args.MaxPrice.String()
-	}
+	q := page.withQ + "SELECT * FROM (SELECT" + listProductColumns + ", CAST(" + page.sortKey + " AS numeric) AS sort_key" +
+		listProductFromQuery + page.where + ") AS listing"
+	queryArgs := append(append(append([]interface{}{}, page.withArgs...), page.sortArgs...), page.whereArgs...)
 
-	if args.Keyword != "" {
-		args.Keyword = strings.ReplaceAll(args.Keyword, "%20", "%")
-		args.Keyword = strings.ReplaceAll(args.Keyword, " ", "%")
-		baseQ += " AND title ILIKE " + "'%" + args.Keyword + "%'"
+	offset := uint64(0)
+	if page.cursor != "" {
+		cursor, err := decodePageCursor(page.cursor)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		sortKey, err := decimal.NewFromString(cursor.SortKey)
+		if err != nil {
+			return nil, 0, "", ErrInvalidCursor
+		}
+		q += " WHERE " + keysetCondition("listing.sort_key", "listing.id", sortOrder)
+		queryArgs = append(queryArgs, sortKey, cursor.ID)
+	} else if page.page > 1 {
+		offset = (page.page - 1) * page.limit
 	}
 
-	if strings.Contains(args.Category, "LV-1") {
-		baseQ += " AND category_lv1_id = " + "'" + args.Category + "'"
-	}
-	if strings.Contains(args.Category, "LV-2") {
-		baseQ += " AND category_lv2_id = " + "'" + args.Category + "'"
-	}
-	if strings.Contains(args.Category, "LV-3") {
-		baseQ += " AND category_lv3_id = " + "'" + args.Category + "'"
-	}
+	/* one extra row tells whether there is a next page */
+	q += " ORDER BY listing.sort_key " + sortOrder + ", listing.id " + sortOrder + " LIMIT ? OFFSET ?"
+	queryArgs = append(queryArgs, page.limit+1, offset)
 
-	if len(args.Locations) > 0 {
-		locationStr := ""
-		for i, loc := range args.Locations {
-			locationStr += strconv.Itoa(int(loc))
-			if i != len(args.Locations)-1 {
-				locationStr += ","
-			}
-		}
-		baseQ += " AND district_code IN (" + locationStr + ") "
+	rows := []listProductRow{}
+	if err := db.WithContext(ctx).Raw(q, queryArgs...).Scan(&rows).Error; err != nil {
+		return nil, 0, "", err
 	}
 
-	additionalSort := ""
-	if args.SortBy != "created_at" {
-		additionalSort = ", created_at DESC"
-	}
-	qForCount := baseQ
-	if args.SortBy == shared.Recommended.Translate() {
-		baseQ += " ORDER BY total_sold DESC, rating DESC"
-	} else {
-		baseQ += " ORDER BY " + args.SortBy + " " + args.SortOrder + additionalSort
+	nextCursor := ""
+	if uint64(len(rows)) > page.limit {
+		rows = rows[:page.limit]
+		last := rows[len(rows)-1]
+		nextCursor = pageCursor{SortKey: last.SortKey.String(), ID: last.ID}.encode()
 	}
-
-	offset := (args.Page - 1) * args.Limit
-	baseQ += " LIMIT ? OFFSET ? "
-
-	query := r.db.WithContext(ctx).Table("products").Raw(
-		baseQ, args.MinRating, args.Limit, offset)
-
-	var totalItems int64
-	if err := r.db.WithContext(ctx).Table("products").Raw(qForCount, args.MinRating).Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProducts: %w", err)
+	for _, row := range rows {
+		products = append(products, row.ListProduct)
 	}
-	totalItems = int64(len(products))
 
-	if err := query.Find(&products).Error; err != nil {
-		return nil, 0, fmt.Errorf("productRepo/ListProducts: %w", err)
-	}
+	return products, uint64(totalItems), nextCursor, nil
+}
 
-	return products, uint64(totalItems), nil
+/* facet counts over the filtered listing, price ranges split the current price span into productPriceFacetBuckets */
+var listProductFacetQuery = `WITH filtered AS (%s),
+	price_span AS (SELECT MIN(min_price) AS lo, MAX(min_price) + 1 AS hi FROM filtered)
+	SELECT * FROM (
+	SELECT 'category_lv1' AS facet, f.category_lv1_id AS value, c.name AS label, COUNT(*) AS count, NULL::numeric AS min_value, NULL::numeric AS max_value
+		FROM filtered f INNER JOIN categories_lv1 c ON c.id = f.category_lv1_id
+		GROUP BY f.category_lv1_id, c.name
+	UNION ALL
+	SELECT 'category_lv2', f.category_lv2_id, c.name, COUNT(*), NULL, NULL
+		FROM filtered f INNER JOIN categories_lv2 c ON c.id = f.category_lv2_id
+		GROUP BY f.category_lv2_id, c.name
+	UNION ALL
+	SELECT 'category_lv3', f.category_lv3_id, c.name, COUNT(*), NULL, NULL
+		FROM filtered f INNER JOIN categories_lv3 c ON c.id = f.category_lv3_id
+		GROUP BY f.category_lv3_id, c.name
+	UNION ALL
+	SELECT 'location', f.district_code::text, f.city, COUNT(*), NULL, NULL
+		FROM filtered f
+		GROUP BY f.district_code, f.city
+	UNION ALL
+	SELECT 'rating', b::text, b::text || '+', COUNT(f.id), b, NULL
+		FROM generate_series(1, 4) b
+		LEFT JOIN filtered f ON f.average_rating >= b
+		GROUP BY b
+	UNION ALL
+	SELECT 'price', pb.bucket::text, '', COUNT(*),
+		FLOOR(s.lo + (pb.bucket - 1) * (s.hi - s.lo) / ?), FLOOR(s.lo + pb.bucket * (s.hi - s.lo) / ?)
+		FROM (SELECT width_bucket(f.min_price, s.lo, s.hi, ?) AS bucket FROM filtered f, price_span s) pb, price_span s
+		GROUP BY pb.bucket, s.lo, s.hi
+	) facets
+	ORDER BY facet, CASE WHEN facet IN ('rating', 'price') THEN 0 ELSE count END DESC, min_value, value`
+
+const productPriceFacetBuckets = 5
+
+func (r *productRepo) ListProductFacets(ctx context.Context, args dto.ListProductQueries) ([]model.ProductFacet, error) {
+	facets := []model.ProductFacet{}
+
+	args.Keyword = strings.TrimSpace(args.Keyword)
+	where, queryArgs := listProductsFilter(args)
+	queryArgs = append(queryArgs, productPriceFacetBuckets, productPriceFacetBuckets, productPriceFacetBuckets)
+
+	if err := r.db.WithContext(ctx).Raw(fmt.Sprintf(listProductFacetQuery, listProductBaseQuery+where), queryArgs...).Scan(&facets).Error; err != nil {
+		return nil, fmt.Errorf("productRepo/ListProductFacets: %w", err)
+	}
+	return facets, nil
 }
 
 func (r *productRepo) SingleListProductByID(ctx context.Context, tx *gorm.DB, productID uint64) (*model.ListProduct, error) {
@@ -256,11 +332,23 @@ func (r *productRepo) SingleListProductByID(ctx context.Context, tx *gorm.DB, pr
 	return product, nil
 }
 
+func (r *productRepo) GetProductIDsByMerchantID(ctx context.Context, merchantID uint64) ([]uint64, error) {
+	productIDs := []uint64{}
+	err := r.db.WithContext(ctx).Model(&model.Product{}).
+		Where("merchant_id = ? AND deleted_at IS NULL", merchantID).
+		Order("id").
+		Pluck("id", &productIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("productRepo/GetProductIDsByMerchantID: %w", err)
+	}
+	return productIDs, nil
+}
+
 func (r *productRepo) GetDetailProduct(c context.Context, id uint64) (model.Product, error) {
 	detailProduct := model.Product{}
 
 	/* query product */
-	if err := r.db.WithContext(c).Model(&model.Product{}).Where("id = ?", id).First(&detailProduct).Error; err != nil {
+	if err := r.db.WithContext(c).Model(&model.Product{}).Where("id = ? AND is_draft IS FALSE AND deleted_at IS NULL", id).First(&detailProduct).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return model.Product{}, fmt.Errorf("productRepo/GetDetailProduct %w", ErrProductNotFound)
 		}
@@ -311,7 +399,9 @@ func (r *productRepo) IsProductExist(c context.Context, id uint64) (model.Produc
 func (r *productRepo) DeactivateProduct(c context.Context, productId uint64) error {
 	tx := r.db.Begin()
 	tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Product{}, productId)
-	if err := tx.WithContext(c).Model(&model.Product{}).Where("id=?", productId).Update("is_active", false).Error; err != nil {
+	/* a scheduled product stays off, the publish job would switch it back on otherwise */
+	updates := map[string]interface{}{"is_active": false, "publish_at": nil}
+	if err := tx.WithContext(c).Model(&model.Product{}).Where("id=?", productId).Updates(updates).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("productRepo/DeactivateProduct %w", ErrInternalServerError)
 	}
@@ -382,23 +472,25 @@ func (r *productRepo) CreateProduct(ctx context.Context, merchantID uint64, crea
 		if err := tx.Create(&product).Error; err != nil {
 			return err
 		}
+		createProductDTO.ID = product.ID
 
 		if err := createProductPhotos(tx, product.ID, createProductDTO.Photos); err != nil {
 			return err
 		}
 
-		var variantProductCreateStrategy VariantProductCreator
-		if createProductDTO.Variants.Child != nil {
-			variantProductCreateStrategy = NewMultiVariantProductCreator(tx, merchantID, createProductDTO)
-		} else {
-			variantProductCreateStrategy = NewSingleVariantProductCreator(tx, merchantID, createProductDTO)
+		if err := tx.Model(product).Updates(productPublication(createProductDTO, false)).Error; err != nil {
+			return err
 		}
 
-		if err := variantProductCreateStrategy.CreateProductVariant(product); err != nil {
+		if err := createProductVariants(tx, merchantID, createProductDTO, product); err != nil {
 			return err
 		}
 
-		return nil
+		stocks, err := variantStocks(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		return recordStockEdit(tx, product.ID, map[uint64]uint64{}, stocks)
 	})
 
 	if err != nil {
@@ -423,15 +515,58 @@ func createProductPhotos(tx *gorm.DB, productID uint64, manageProductPhotosDTO [
 	return nil
 }
 
+func createProductVariants(tx *gorm.DB, merchantID uint64, productDTO *dto.ManageProduct, product *model.Product) error {
+	if len(productDTO.Variants.Combinations) == 0 {
+		return nil
+	}
+
+	var variantProductCreateStrategy VariantProductCreator
+	if productDTO.Variants.Child != nil {
+		variantProductCreateStrategy = NewMultiVariantProductCreator(tx, merchantID, productDTO)
+	} else {
+		variantProductCreateStrategy = NewSingleVariantProductCreator(tx, merchantID, productDTO)
+	}
+	return variantProductCreateStrategy.CreateProductVariant(product)
+}
+
+/*
+columns deciding whether a saved product is visible. drafts are hidden, a completed product is shown right away
+unless it has a publish time in the future. a product that was already published keeps its current visibility
+*/
+func productPublication(productDTO *dto.ManageProduct, published bool) map[string]interface{} {
+	updates := map[string]interface{}{"is_draft": productDTO.IsDraft, "publish_at": productDTO.PublishAt}
+	switch {
+	case productDTO.IsDraft:
+		updates["is_active"] = false
+	case published:
+		updates["publish_at"] = nil
+	case productDTO.PublishAt != nil && productDTO.PublishAt.After(time.Now()):
+		updates["is_active"] = false
+	default:
+		updates["is_active"] = true
+		updates["publish_at"] = nil
+	}
+	return updates
+}
+
 func (r *productRepo) UpdateProduct(ctx context.Context, merchantID uint64, updateProductDTO *dto.ManageProduct) error {
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		product := updateProductDTO.ToProductModel()
 		product.MerchantId = merchantID
 		product.ID = updateProductDTO.ID
 
+		current := model.Product{}
+		if err := tx.Select("is_draft", "publish_at").Where("id = ?", product.ID).First(&current).Error; err != nil {
+			return err
+		}
+		published := !current.IsDraft && current.PublishAt == nil
+
 		if err := tx.Updates(&product).Error; err != nil {
 			return err
 		}
+		if err := tx.Model(product).Updates(productPublication(updateProductDTO, published)).Error; err != nil {
+			return err
+		}
 
 		if err := updateProductPhotos(tx, updateProductDTO.ID, updateProductDTO.Photos); err != nil {
 			return err
@@ -444,17 +579,36 @@ func (r *productRepo) UpdateProduct(ctx context.Context, merchantID uint64, upda
 			return err
 		}
 
+		stocksBefore, err := variantStocks(tx, product.ID)
+		if err != nil {
+			return err
+		}
+
 		var variantUpdateStrategy VariantProductUpdater
 
 		switch {
+		case len(combinations) == 0:
+			/* a draft saved without variants gets them the same way a new product does */
+			if err := createProductVariants(tx, merchantID, updateProductDTO, product); err != nil {
+				return err
+			}
 		case variantsDTO.Parent.ID != 0 && (variantsDTO.Child != nil && variantsDTO.Child.ID != 0):
 			variantUpdateStrategy = NewMultiVariantExistingGroupUpdater(tx, updateProductDTO, combinations)
-			variantUpdateStrategy.UpdateProductVariant(product)
+			if err := variantUpdateStrategy.UpdateProductVariant(product); err != nil {
+				return err
+			}
 		case variantsDTO.Parent.ID != 0 && variantsDTO.Child == nil:
 			variantUpdateStrategy = NewSingleVariantExistingGroupUpdater(tx, updateProductDTO, combinations)
-			variantUpdateStrategy.UpdateProductVariant(product)
+			if err := variantUpdateStrategy.UpdateProductVariant(product); err != nil {
+				return err
+			}
 		}
-		return nil
+
+		stocksAfter, err := variantStocks(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		return recordStockEdit(tx, product.ID, stocksBefore, stocksAfter)
 	})
 
 	if err != nil {
@@ -1089,3 +1243,86 @@ func (pu *singleVariantExistingGroupUpdater) UpdateProductVariant(product *model
 	}
 	return nil
 }
+
+/* drafts and products waiting for their publish time, the ones that publish first come first */
+func (r *productRepo) GetDraftProducts(ctx context.Context, merchantID uint64) ([]model.Product, error) {
+	products := []model.Product{}
+	err := r.db.WithContext(ctx).Model(&model.Product{}).
+		Where("merchant_id = ? AND deleted_at IS NULL AND (is_draft IS TRUE OR publish_at IS NOT NULL)", merchantID).
+		Order("is_draft, publish_at, updated_at DESC").
+		Find(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("productRepo/GetDraftProducts: %w", err)
+	}
+	return products, nil
+}
+
+/* activates completed products whose publish time has passed and returns their ids */
+func (r *productRepo) PublishScheduledProducts(ctx context.Context) ([]uint64, error) {
+	published := []model.Product{}
+	err := r.db.WithContext(ctx).Raw(`UPDATE products
+		SET is_active = TRUE, publish_at = NULL, updated_at = NOW()
+		WHERE is_draft IS FALSE AND publish_at <= NOW() AND deleted_at IS NULL
+		RETURNING id`).Scan(&published).Error
+	if err != nil {
+		return nil, fmt.Errorf("productRepo/PublishScheduledProducts: %w", err)
+	}
+	ids := []uint64{}
+	for _, p := range published {
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+/*
+a deleted product is hidden and can be restored, orders keep their own copy of the product so only carts are
+cleaned up. the variants stay in place for the order history and the stock ledger
+*/
+func (r *productRepo) DeleteProduct(ctx context.Context, productID uint64) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Product{}).Where("id = ? AND deleted_at IS NULL", productID).Updates(map[string]interface{}{
+			"deleted_at": gorm.Expr("NOW()"),
+			"is_active":  false,
+			"updated_at": gorm.Expr("NOW()"),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrProductNotFound
+		}
+		return tx.Exec(`DELETE FROM cart_products
+			WHERE variant_combination_product_id IN (SELECT id FROM variant_combination_products WHERE product_id = ?)`, productID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("productRepo/DeleteProduct: %w", err)
+	}
+	return nil
+}
+
+/* a restored product comes back inactive so the merchant can check it before it is sold again */
+func (r *productRepo) RestoreProduct(ctx context.Context, productID uint64) error {
+	res := r.db.WithContext(ctx).Model(&model.Product{}).Where("id = ? AND deleted_at IS NOT NULL", productID).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"updated_at": gorm.Expr("NOW()"),
+	})
+	if res.Error != nil {
+		return fmt.Errorf("productRepo/RestoreProduct: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("productRepo/RestoreProduct: %w", ErrProductNotArchived)
+	}
+	return nil
+}
+
+func (r *productRepo) GetArchivedProducts(ctx context.Context, merchantID uint64) ([]model.Product, error) {
+	products := []model.Product{}
+	err := r.db.WithContext(ctx).Model(&model.Product{}).
+		Where("merchant_id = ? AND deleted_at IS NOT NULL", merchantID).
+		Order("deleted_at DESC").
+		Find(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("productRepo/GetArchivedProducts: %w", err)
+	}
+	return products, nil
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"digital-test-vm/be/internal/dto"
+	"testing"
+	"time"
+)
+
+func TestProductPublication(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		product   dto.ManageProduct
+		published bool
+		isDraft   bool
+		isActive  *bool
+		publishAt *time.Time
+	}{
+		{
+			name:     "draft is hidden",
+			product:  dto.ManageProduct{IsDraft: true},
+			isDraft:  true,
+			isActive: boolPtr(false),
+		},
+		{
+			name:      "draft keeps its publish time",
+			product:   dto.ManageProduct{IsDraft: true, PublishAt: &future},
+			isDraft:   true,
+			isActive:  boolPtr(false),
+			publishAt: &future,
+		},
+		{
+			name:     "completed product is shown right away",
+			product:  dto.ManageProduct{},
+			isActive: boolPtr(true),
+		},
+		{
+			name:      "publish time in the future waits hidden",
+			product:   dto.ManageProduct{PublishAt: &future},
+			isActive:  boolPtr(false),
+			publishAt: &future,
+		},
+		{
+			name:     "publish time already passed is shown right away",
+			product:  dto.ManageProduct{PublishAt: &past},
+			isActive: boolPtr(true),
+		},
+		{
+			name:      "published product keeps its visibility",
+			product:   dto.ManageProduct{PublishAt: &future},
+			published: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := productPublication(&tt.product, tt.published)
+
+			if updates["is_draft"] != tt.isDraft {
+				t.Errorf("is_draft = %v, want %v", updates["is_draft"], tt.isDraft)
+			}
+			isActive, set := updates["is_active"]
+			if tt.isActive == nil && set {
+				t.Errorf("is_active = %v, want it left alone", isActive)
+			}
+			if tt.isActive != nil && isActive != *tt.isActive {
+				t.Errorf("is_active = %v, want %v", isActive, *tt.isActive)
+			}
+			publishAt, _ := updates["publish_at"].(*time.Time)
+			if (publishAt == nil) != (tt.publishAt == nil) || (publishAt != nil && !publishAt.Equal(*tt.publishAt)) {
+				t.Errorf("publish_at = %v, want %v", updates["publish_at"], tt.publishAt)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -5,7 +5,11 @@ import (
 	"digital-test-vm/be/internal/dto"
 	"digital-test-vm/be/internal/model"
 	"digital-test-vm/be/internal/shared"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -16,44 +20,78 @@ type productReviewRepo struct {
 }
 
 type ProductReviewRepo interface {
-	GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, error)
-	GetAllProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, error)
-	CreateProductReview(c context.Context, req model.ProductReview, product model.Product) error
+	GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, string, error)
+	CountProductReview(c context.Context, req dto.GetProductReviewRequest) (int64, error)
+	CreateProductReview(c context.Context, req model.ProductReview) error
+	UpdateProductReview(c context.Context, review model.ProductReview, photos []string) error
+	DeleteProductReview(c context.Context, review model.ProductReview) error
 	IsProductReviewed(c context.Context, userId, productId, orderDetailId uint64) bool
+	GetReview(c context.Context, id uint64) (model.ProductReview, error)
+	ReplyReview(c context.Context, id uint64, reply string) error
+	EditReviewReply(c context.Context, id uint64, reply string, window time.Duration) error
+	GetMerchantReviews(c context.Context, merchantId uint64, cursor string, limit int, unrepliedOnly bool) ([]model.MerchantReview, string, error)
+	VoteHelpful(c context.Context, reviewId, userId uint64) error
+	UnvoteHelpful(c context.Context, reviewId, userId uint64) error
+	GetHelpfulVotes(c context.Context, userId uint64, reviewIds []uint64) (map[uint64]bool, error)
 }
 
 func NewProductReviewRepo(db *gorm.DB) ProductReviewRepo {
 	return &productReviewRepo{db: db}
 }
 
-func (r *productReviewRepo) GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, error) {
+func (r *productReviewRepo) GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, string, error) {
 	res := []model.ProductReview{}
-	q := r.db.WithContext(c).Model(&model.ProductReview{}).Where("product_id = ?", req.ProductId)
-	if req.Rating > 0 && req.Rating < 6 {
-		q = q.Where("rating=?", req.Rating)
+	q := r.filterProductReview(c, req)
+
+	/* most helpful always lists the highest count first, votes cast while paging can move a review across pages */
+	sortKey, order := "created_at", req.Order
+	if req.SortBy == shared.ReviewSortHelpful.String() {
+		sortKey, order = "helpful_count", "desc"
 	}
-	if req.Comments == "true" {
-		q = q.Where("description is not null")
+	if req.Cursor != "" {
+		cursor, err := decodePageCursor(req.Cursor)
+		if err != nil {
+			return []model.ProductReview{}, "", fmt.Errorf("productReviewRepo/GetProductReview %w", err)
+		}
+		var sortValue interface{}
+		if sortKey == "helpful_count" {
+			sortValue, err = strconv.ParseUint(cursor.SortKey, 10, 64)
+		} else {
+			sortValue, err = time.Parse(time.RFC3339Nano, cursor.SortKey)
+		}
+		if err != nil {
+			return []model.ProductReview{}, "", fmt.Errorf("productReviewRepo/GetProductReview %w", ErrInvalidCursor)
+		}
+		q = q.Where(keysetCondition(sortKey, "id", strings.ToUpper(order)), sortValue, cursor.ID)
+	} else {
+		q = q.Offset((req.Page - 1) * req.Limit)
 	}
-	if req.Comments == "false" {
-		q = q.Where("description is null")
+	if err := q.Order(sortKey + " " + order + ", id " + order).Limit(req.Limit + 1).Find(&res).Error; err != nil {
+		return []model.ProductReview{}, "", fmt.Errorf("productReviewRepo/GetProductReview %w", ErrInternalServerError)
 	}
-	if req.Images == "true" {
-		q = q.Where("photos is not null")
-	}
-	if req.Images == "false" {
-		q = q.Where("photos is null")
+
+	nextCursor := ""
+	if len(res) > req.Limit {
+		res = res[:req.Limit]
+		last := res[len(res)-1]
+		cursor := pageCursor{SortKey: last.CreatedAt.Format(time.RFC3339Nano), ID: last.ID}
+		if sortKey == "helpful_count" {
+			cursor.SortKey = strconv.FormatUint(last.HelpfulCount, 10)
+		}
+		nextCursor = cursor.encode()
 	}
-	q = q.Where("deleted_at is null")
-	offset := (req.Page - 1) * req.Limit
-	if err := q.Order("created_at " + req.Order).Offset(offset).Limit(req.Limit).Find(&res).Error; err != nil {
-		return []model.ProductReview{}, fmt.Errorf("productReviewRepo/GetProductReview %w", ErrInternalServerError)
+	return res, nextCursor, nil
+}
+
+func (r *productReviewRepo) CountProductReview(c context.Context, req dto.GetProductReviewRequest) (int64, error) {
+	var total int64
+	if err := r.filterProductReview(c, req).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("productReviewRepo/CountProductReview %w", shared.ErrInternalServerError)
 	}
-	return res, nil
+	return total, nil
 }
 
-func (r *productReviewRepo) GetAllProductReview(c context.Context, req dto.GetProductReviewRequest) ([]model.ProductReview, error) {
-	res := []model.ProductReview{}
+func (r *productReviewRepo) filterProductReview(c context.Context, req dto.GetProductReviewRequest) *gorm.DB {
 	q := r.db.WithContext(c).Model(&model.ProductReview{}).Where("product_id = ?", req.ProductId)
 	if req.Rating > 0 && req.Rating < 6 {
 		q = q.Where("rating=?", req.Rating)
@@ -70,29 +108,112 @@ func (r *productReviewRepo) GetAllProductReview(c context.Context, req dto.GetPr
 	if req.Images == "false" {
 		q = q.Where("photos is null")
 	}
-	if err := q.Where("deleted_at is null").Find(&res).Error; err != nil {
-		return res, fmt.Errorf("productReviewRepo/GetAllProductReview %w", shared.ErrInternalServerError)
+	if req.WithMedia {
+		q = q.Where(`(photos is not null OR EXISTS (
+			SELECT 1 FROM product_review_photos prp WHERE prp.product_review_id = product_reviews.id AND prp.deleted_at is null))`)
 	}
-	return res, nil
+	return q.Where("deleted_at is null")
 }
 
-func (r *productReviewRepo) CreateProductReview(c context.Context, req model.ProductReview, product model.Product) error {
-	tx := r.db.Begin()
-	tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Product{}, req.ProductId)
-	newRating := ((product.AverageRating * float64(product.TotalRating)) + float64(req.Rating)) / float64(product.TotalRating+1)
-	if err := tx.WithContext(c).Model(&model.Product{}).Where("id=?", req.ProductId).Update("average_rating", newRating).Update("total_rating", gorm.Expr("total_rating+1")).Error; err != nil {
-		tx.Rollback()
+func (r *productReviewRepo) CreateProductReview(c context.Context, req model.ProductReview) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, req.ProductId); err != nil {
+			return err
+		}
+		if err := tx.Create(&req).Error; err != nil {
+			return err
+		}
+		return refreshReviewRatings(tx, req.ProductId)
+	})
+	if err != nil {
 		return fmt.Errorf("productReviewRepo/CreateProductReview %w", ErrInternalServerError)
 	}
-	if err := r.db.WithContext(c).Create(&req).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("productReviewRepo/CreateProductReview %w", ErrInternalServerError)
+	return nil
+}
+
+/* photos replace the previous ones, the rating of the product and its merchant follow the new rating */
+func (r *productReviewRepo) UpdateProductReview(c context.Context, review model.ProductReview, photos []string) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, review.ProductId); err != nil {
+			return err
+		}
+		err := tx.Model(&model.ProductReview{}).Where("id = ?", review.ID).Updates(map[string]interface{}{
+			"rating":      review.Rating,
+			"description": nullableText(review.Description),
+			"photos":      nullableText(review.Photos),
+			"updated_at":  gorm.Expr("NOW()"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ProductReviewPhoto{}).Where("product_review_id = ? AND deleted_at IS NULL", review.ID).
+			Update("deleted_at", gorm.Expr("NOW()")).Error; err != nil {
+			return err
+		}
+		if len(photos) > 0 {
+			rows := make([]model.ProductReviewPhoto, len(photos))
+			for i, url := range photos {
+				rows[i] = model.ProductReviewPhoto{ProductReviewId: review.ID, Url: url, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		return refreshReviewRatings(tx, review.ProductId)
+	})
+	if err != nil {
+		return fmt.Errorf("productReviewRepo/UpdateProductReview: %w", err)
 	}
-	tx.Commit()
+	return nil
+}
 
+func (r *productReviewRepo) DeleteProductReview(c context.Context, review model.ProductReview) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, review.ProductId); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ProductReview{}).Where("id = ?", review.ID).Update("deleted_at", gorm.Expr("NOW()")).Error; err != nil {
+			return err
+		}
+		return refreshReviewRatings(tx, review.ProductId)
+	})
+	if err != nil {
+		return fmt.Errorf("productReviewRepo/DeleteProductReview: %w", err)
+	}
 	return nil
 }
 
+/* reviews of one product are written one at a time so every recount sees the reviews committed before it */
+func lockProduct(tx *gorm.DB, productId uint64) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Product{}, productId).Error
+}
+
+/*
+recounts the rating of a product and of its merchant from the reviews that are left instead of adjusting the
+running average, so edits and deletions cannot make the average drift
+*/
+func refreshReviewRatings(tx *gorm.DB, productId uint64) error {
+	err := tx.Exec(`UPDATE products p
+		SET average_rating = r.average, total_rating = r.total
+		FROM (
+			SELECT COALESCE(AVG(rating), 0) AS average, COUNT(*) AS total
+			FROM product_reviews
+			WHERE product_id = ? AND deleted_at IS NULL
+		) r
+		WHERE p.id = ?`, productId, productId).Error
+	if err != nil {
+		return err
+	}
+	return refreshMerchantRating(tx, "m.id = (SELECT merchant_id FROM products WHERE id = ?)", productId)
+}
+
+func nullableText(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	return text
+}
+
 func (r *productReviewRepo) IsProductReviewed(c context.Context, userId, productId, orderDetailId uint64) bool {
 	var res model.ProductReview
 	if err := r.db.Debug().WithContext(c).Model(&model.ProductReview{}).Where("product_id=? and user_id=? and order_detail_id=?", productId, userId, orderDetailId).First(&res).Error; err != nil {
@@ -100,3 +221,126 @@ func (r *productReviewRepo) IsProductReviewed(c context.Context, userId, product
 	}
 	return true
 }
+
+func (r *productReviewRepo) GetReview(c context.Context, id uint64) (model.ProductReview, error) {
+	res := model.ProductReview{}
+	if err := r.db.WithContext(c).Where("id = ? AND deleted_at IS NULL", id).First(&res).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ProductReview{}, fmt.Errorf("productReviewRepo/GetReview: %w", ErrReviewNotFound)
+		}
+		return model.ProductReview{}, fmt.Errorf("productReviewRepo/GetReview: %w", err)
+	}
+	return res, nil
+}
+
+/* the reply is only written when there is none yet so two merchant sessions cannot overwrite each other */
+func (r *productReviewRepo) ReplyReview(c context.Context, id uint64, reply string) error {
+	res := r.db.WithContext(c).Model(&model.ProductReview{}).
+		Where("id = ? AND reply IS NULL", id).
+		Updates(map[string]interface{}{
+			"reply":      reply,
+			"replied_at": gorm.Expr("NOW()"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("productReviewRepo/ReplyReview: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("productReviewRepo/ReplyReview: %w", ErrReviewReplied)
+	}
+	return nil
+}
+
+func (r *productReviewRepo) EditReviewReply(c context.Context, id uint64, reply string, window time.Duration) error {
+	res := r.db.WithContext(c).Model(&model.ProductReview{}).
+		Where("id = ? AND reply IS NOT NULL AND reply_edited_at IS NULL AND replied_at > ?", id, time.Now().Add(-window)).
+		Updates(map[string]interface{}{
+			"reply":           reply,
+			"reply_edited_at": gorm.Expr("NOW()"),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("productReviewRepo/EditReviewReply: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("productReviewRepo/EditReviewReply: %w", ErrReplyNotEditable)
+	}
+	return nil
+}
+
+/* newest first across every product of the merchant */
+func (r *productReviewRepo) GetMerchantReviews(c context.Context, merchantId uint64, cursor string, limit int, unrepliedOnly bool) ([]model.MerchantReview, string, error) {
+	res := []model.MerchantReview{}
+	q := r.db.WithContext(c).Table("product_reviews pr").
+		Select("pr.id, pr.product_id, p.title AS product_title, u.first_name AS user_name, pr.rating, pr.description, pr.reply, pr.replied_at, pr.reply_edited_at, pr.created_at").
+		Joins("INNER JOIN products p ON p.id = pr.product_id").
+		Joins("INNER JOIN users u ON u.id = pr.user_id").
+		Where("p.merchant_id = ? AND pr.deleted_at IS NULL", merchantId)
+	if unrepliedOnly {
+		q = q.Where("pr.reply IS NULL")
+	}
+	if cursor != "" {
+		pc, err := decodePageCursor(cursor)
+		if err != nil {
+			return []model.MerchantReview{}, "", fmt.Errorf("productReviewRepo/GetMerchantReviews: %w", err)
+		}
+		q = q.Where("pr.id < ?", pc.ID)
+	}
+	if err := q.Order("pr.id DESC").Limit(limit + 1).Scan(&res).Error; err != nil {
+		return []model.MerchantReview{}, "", fmt.Errorf("productReviewRepo/GetMerchantReviews: %w", err)
+	}
+
+	nextCursor := ""
+	if len(res) > limit {
+		res = res[:limit]
+		nextCursor = pageCursor{ID: res[len(res)-1].ID}.encode()
+	}
+	return res, nextCursor, nil
+}
+
+/* voting twice is a no-op, the count only moves when a vote row is actually added */
+func (r *productReviewRepo) VoteHelpful(c context.Context, reviewId, userId uint64) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		vote := tx.Exec(`INSERT INTO review_helpful_votes (product_review_id, user_id) VALUES (?, ?)
+			ON CONFLICT (product_review_id, user_id) DO NOTHING`, reviewId, userId)
+		if vote.Error != nil || vote.RowsAffected == 0 {
+			return vote.Error
+		}
+		return tx.Exec("UPDATE product_reviews SET helpful_count = helpful_count + 1 WHERE id = ?", reviewId).Error
+	})
+	if err != nil {
+		return fmt.Errorf("productReviewRepo/VoteHelpful: %w", err)
+	}
+	return nil
+}
+
+func (r *productReviewRepo) UnvoteHelpful(c context.Context, reviewId, userId uint64) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		vote := tx.Where("product_review_id = ? AND user_id = ?", reviewId, userId).Delete(&model.ReviewHelpfulVote{})
+		if vote.Error != nil || vote.RowsAffected == 0 {
+			return vote.Error
+		}
+		return tx.Exec("UPDATE product_reviews SET helpful_count = GREATEST(helpful_count - 1, 0) WHERE id = ?", reviewId).Error
+	})
+	if err != nil {
+		return fmt.Errorf("productReviewRepo/UnvoteHelpful: %w", err)
+	}
+	return nil
+}
+
+/* reviews of the page the user already marked helpful */
+func (r *productReviewRepo) GetHelpfulVotes(c context.Context, userId uint64, reviewIds []uint64) (map[uint64]bool, error) {
+	voted := map[uint64]bool{}
+	if len(reviewIds) == 0 {
+		return voted, nil
+	}
+	ids := []uint64{}
+	err := r.db.WithContext(c).Model(&model.ReviewHelpfulVote{}).
+		Where("user_id = ? AND product_review_id IN ?", userId, reviewIds).
+		Pluck("product_review_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("productReviewRepo/GetHelpfulVotes: %w", err)
+	}
+	for _, id := range ids {
+		voted[id] = true
+	}
+	return voted, nil
+}
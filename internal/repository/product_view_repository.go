@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	recentlyViewedKeyPrefix = "recently-viewed:"
+	recentlyViewedCap       = 50
+	recentlyViewedTTL       = 30 * 24 * time.Hour
+
+	productViewSeenKeyPrefix = "product-view-seen:"
+	productViewDedupeWindow  = 30 * time.Minute
+	productViewPendingKey    = "product-views:pending"
+	productViewFlushingKey   = "product-views:flushing"
+	trendingWindowDays       = 7
+)
+
+type ProductViewRepo interface {
+	RecordView(ctx context.Context, viewerID *uint64, viewerIP string, productID uint64) error
+	FlushViews(ctx context.Context) error
+	RefreshTrending(ctx context.Context) error
+	ListRecentlyViewedProducts(ctx context.Context, userID uint64, limit int64) ([]model.ListProduct, error)
+}
+
+type productViewRepo struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewProductViewRepo(db *gorm.DB, redis *redis.Client) ProductViewRepo {
+	return &productViewRepo{
+		db:    db,
+		redis: redis,
+	}
+}
+
+func recentlyViewedKey(userID uint64) string {
+	return recentlyViewedKeyPrefix + strconv.FormatUint(userID, 10)
+}
+
+/* logged in viewers are told apart by their id, anonymous ones by their ip */
+func productViewSeenKey(viewerID *uint64, viewerIP string, productID uint64) string {
+	viewer := "ip:" + viewerIP
+	if viewerID != nil {
+		viewer = "user:" + strconv.FormatUint(*viewerID, 10)
+	}
+	return productViewSeenKeyPrefix + strconv.FormatUint(productID, 10) + ":" + viewer
+}
+
+/*
+a viewer counts once per product in every dedupe window. the count is buffered in redis and written to the
+database by FlushViews, only logged in viewers get a history
+*/
+func (r *productViewRepo) RecordView(ctx context.Context, viewerID *uint64, viewerIP string, productID uint64) error {
+	first, err := r.redis.SetNX(ctx, productViewSeenKey(viewerID, viewerIP, productID), 1, productViewDedupeWindow).Result()
+	if err != nil {
+		return fmt.Errorf("productViewRepo/RecordView: %w", err)
+	}
+	if first {
+		if err := r.redis.HIncrBy(ctx, productViewPendingKey, strconv.FormatUint(productID, 10), 1).Err(); err != nil {
+			return fmt.Errorf("productViewRepo/RecordView: %w", err)
+		}
+	}
+
+	if viewerID == nil {
+		return nil
+	}
+
+	key := recentlyViewedKey(*viewerID)
+	_, err = r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, key, 0, productID)
+		pipe.LPush(ctx, key, productID)
+		pipe.LTrim(ctx, key, 0, recentlyViewedCap-1)
+		pipe.Expire(ctx, key, recentlyViewedTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("productViewRepo/RecordView: %w", err)
+	}
+	return nil
+}
+
+/*
+the buffered counts are moved aside before they are written so views recorded meanwhile wait for the next flush.
+a flush that failed leaves its counts aside and they are written first on the next run
+*/
+func (r *productViewRepo) FlushViews(ctx context.Context) error {
+	flushing, err := r.redis.Exists(ctx, productViewFlushingKey).Result()
+	if err != nil {
+		return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+	}
+	if flushing == 0 {
+		pending, err := r.redis.Exists(ctx, productViewPendingKey).Result()
+		if err != nil {
+			return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+		}
+		if pending == 0 {
+			return nil
+		}
+		if err := r.redis.Rename(ctx, productViewPendingKey, productViewFlushingKey).Err(); err != nil {
+			return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+		}
+	}
+
+	counts, err := r.redis.HGetAll(ctx, productViewFlushingKey).Result()
+	if err != nil {
+		return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productIDs := []uint64{}
+		for field, value := range counts {
+			productID, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				continue
+			}
+			views, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			err = tx.Exec(`INSERT INTO product_daily_views (product_id, view_date, views) VALUES (?, CURRENT_DATE, ?)
+				ON CONFLICT (product_id, view_date) DO UPDATE SET views = product_daily_views.views + EXCLUDED.views`,
+				productID, views).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE products SET view_count = view_count + ? WHERE id = ?", views, productID).Error; err != nil {
+				return err
+			}
+			productIDs = append(productIDs, productID)
+		}
+		if len(productIDs) == 0 {
+			return nil
+		}
+		return tx.Exec(refreshRecentViewsQuery+" WHERE p.id IN ?", trendingWindowDays, productIDs).Error
+	})
+	if err != nil {
+		return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+	}
+
+	if err := r.redis.Del(ctx, productViewFlushingKey).Err(); err != nil {
+		return fmt.Errorf("productViewRepo/FlushViews: %w", err)
+	}
+	return nil
+}
+
+var refreshRecentViewsQuery = `UPDATE products p SET recent_view_count = COALESCE((SELECT SUM(v.views) FROM product_daily_views v
+	WHERE v.product_id = p.id AND v.view_date > CURRENT_DATE - CAST(? AS INTEGER)), 0)`
+
+/* days that left the trending window are dropped from the products that were viewed in it */
+func (r *productViewRepo) RefreshTrending(ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(refreshRecentViewsQuery+" WHERE p.recent_view_count > 0", trendingWindowDays).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM product_daily_views WHERE view_date <= CURRENT_DATE - CAST(? AS INTEGER)", trendingWindowDays).Error
+	})
+	if err != nil {
+		return fmt.Errorf("productViewRepo/RefreshTrending: %w", err)
+	}
+	return nil
+}
+
+func (r *productViewRepo) ListRecentlyViewedProducts(ctx context.Context, userID uint64, limit int64) ([]model.ListProduct, error) {
+	products := []model.ListProduct{}
+
+	members, err := r.redis.LRange(ctx, recentlyViewedKey(userID), 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("productViewRepo/ListRecentlyViewedProducts: %w", err)
+	}
+
+	productIDs := []uint64{}
+	for _, m := range members {
+		id, err := strconv.ParseUint(m, 10, 64)
+		if err == nil {
+			productIDs = append(productIDs, id)
+		}
+	}
+	if len(productIDs) == 0 {
+		return products, nil
+	}
+
+	found := []model.ListProduct{}
+	q := listProductBaseQuery + " WHERE p.id IN ? AND p.deleted_at IS NULL"
+	if err := r.db.WithContext(ctx).Raw(q, productIDs).Scan(&found).Error; err != nil {
+		return nil, fmt.Errorf("productViewRepo/ListRecentlyViewedProducts: %w", err)
+	}
+
+	/* keep the most recent view first, products removed since then are skipped */
+	byID := make(map[uint64]model.ListProduct, len(found))
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+	for _, id := range productIDs {
+		if p, ok := byID[id]; ok {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
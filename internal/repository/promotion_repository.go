@@ -5,6 +5,7 @@ import (
 	"digital-test-vm/be/internal/dto"
 	"digital-test-vm/be/internal/model"
 	"digital-test-vm/be/internal/shared"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,6 +18,11 @@ type PromotionRepo interface {
 	CreateProductPromotion(ctx context.Context, tx *gorm.DB, merchantProductPromo *model.MerchantProductPromotion) error
 	CreateMerchantPromotion(ctx context.Context, merchantID uint64, createPromotionDTO *dto.ManagePromotion) error
 	ListPromotionsByMerchantID(ctx context.Context, tx *gorm.DB, merchantID uint64, args dto.ListPromotionQueries) ([]model.Promotion, uint64, error)
+	CreateGlobalPromotion(ctx context.Context, createPromotionDTO *dto.ManagePromotion) error
+	GetPromotionByID(ctx context.Context, tx *gorm.DB, promoID uint64) (*model.Promotion, error)
+	ListGlobalPromotions(ctx context.Context, tx *gorm.DB, args dto.ListPromotionQueries) ([]model.Promotion, uint64, error)
+	GetPromotionMerchantID(ctx context.Context, tx *gorm.DB, promoID uint64) (*uint64, error)
+	GetPromotionReport(ctx context.Context, promotion *model.Promotion, merchantID *uint64) (*model.PromotionReport, []model.PromotionReport, error)
 }
 
 type promotionRepo struct {
@@ -99,6 +105,28 @@ func (r *promotionRepo) CreateMerchantPromotion(ctx context.Context, merchantID
 	return nil
 }
 
+func (r *promotionRepo) CreateGlobalPromotion(ctx context.Context, createPromotionDTO *dto.ManagePromotion) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		promotion, err := r.CreatePromotion(ctx, tx, createPromotionDTO.Promotion.ToModel())
+		if err != nil {
+			return err
+		}
+
+		/* global promotions are linked without merchant and product */
+		merchantProductPromo := &model.MerchantProductPromotion{
+			PromotionID: promotion.ID,
+		}
+		if err := r.CreateProductPromotion(ctx, tx, merchantProductPromo); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("promotionRepo/CreateGlobalPromotion: %w", err)
+	}
+	return nil
+}
+
 func (r *promotionRepo) UpdatePromotion(ctx context.Context, tx *gorm.DB, promotion *model.Promotion) (*model.Promotion, error) {
 	var db *gorm.DB
 	if tx != nil {
@@ -123,12 +151,49 @@ func (r *promotionRepo) GetPromotionByID(ctx context.Context, tx *gorm.DB, promo
 	}
 
 	var promotion model.Promotion
-	if err := db.WithContext(ctx).First(&promotion).Error; err != nil {
+	if err := db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", promoID).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrPromotionNotFound
+		}
 		return nil, fmt.Errorf("promotionRepo/GetPromotionByID: %w", err)
 	}
 	return &promotion, nil
 }
 
+func (r *promotionRepo) ListGlobalPromotions(ctx context.Context, tx *gorm.DB, args dto.ListPromotionQueries) ([]model.Promotion, uint64, error) {
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+
+	q := db.WithContext(ctx).Model(&model.Promotion{}).
+		Where("promotion_scope = ? AND deleted_at IS NULL", shared.GlobalScope.String())
+
+	now := time.Now()
+	switch args.Status.String() {
+	case shared.PromotionStatusOngoing.String():
+		q = q.Where("start_date <= ? AND end_date >= ?", now, now)
+	case shared.PromotionStatusWillCome.String():
+		q = q.Where("start_date > ?", now)
+	case shared.PromotionStatusEnded.String():
+		q = q.Where("end_date < ?", now)
+	}
+
+	var totalItems int64
+	if err := q.Count(&totalItems).Error; err != nil {
+		return nil, 0, fmt.Errorf("promotionRepo/ListGlobalPromotions: %w", err)
+	}
+
+	promotions := []model.Promotion{}
+	offset := (args.Page - 1) * args.Limit
+	if err := q.Order("id DESC").Limit(int(args.Limit)).Offset(int(offset)).Find(&promotions).Error; err != nil {
+		return nil, 0, fmt.Errorf("promotionRepo/ListGlobalPromotions: %w", err)
+	}
+	return promotions, uint64(totalItems), nil
+}
+
 func (r *promotionRepo) ListPromotionsByMerchantID(ctx context.Context, tx *gorm.DB, merchantID uint64, args dto.ListPromotionQueries) ([]model.Promotion, uint64, error) {
 	var db *gorm.DB
 	if tx != nil {
@@ -198,3 +263,68 @@ func (r *promotionRepo) ListPromotionsByMerchantID(ctx context.Context, tx *gorm
 	}
 	return newPromotions, uint64(totalItems), nil
 }
+
+func (r *promotionRepo) GetPromotionMerchantID(ctx context.Context, tx *gorm.DB, promoID uint64) (*uint64, error) {
+	var db *gorm.DB
+	if tx != nil {
+		db = tx
+	} else {
+		db = r.db
+	}
+
+	var merchantProductPromo model.MerchantProductPromotion
+	err := db.WithContext(ctx).Where("promotion_id = ? AND deleted_at IS NULL", promoID).First(&merchantProductPromo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("promotionRepo/GetPromotionMerchantID: %w", err)
+	}
+	if merchantProductPromo.MerchantID == 0 {
+		return nil, nil
+	}
+	return &merchantProductPromo.MerchantID, nil
+}
+
+func (r *promotionRepo) GetPromotionReport(ctx context.Context, promotion *model.Promotion, merchantID *uint64) (*model.PromotionReport, []model.PromotionReport, error) {
+	var selectQ, fromQ string
+	args := []interface{}{promotion.ID, shared.Canceled.String()}
+
+	/* bundles are applied per line without a voucher, vouchers are stored on the order */
+	if promotion.PromotionType == shared.BuyXGetY.String() || promotion.PromotionType == shared.Bundle.String() {
+		selectQ = `COUNT(DISTINCT od.order_id) AS redemptions,
+			COALESCE(SUM(odp.bundle_saving), 0) AS total_discount,
+			COALESCE(SUM(odp.final_price), 0) AS gmv,
+			COUNT(DISTINCT c.user_id) AS unique_buyers`
+		fromQ = ` FROM order_detail_products odp
+			INNER JOIN order_details od ON od.id = odp.order_detail_id
+			INNER JOIN orders o ON o.id = od.order_id
+			INNER JOIN carts c ON c.id = o.cart_id
+			WHERE odp.bundle_promotion_id = ? AND od.order_status <> ?`
+	} else {
+		selectQ = `COUNT(DISTINCT o.id) AS redemptions,
+			COALESCE(SUM(od.initial_price - od.final_price + od.platform_discount + od.shipping_discount), 0) AS total_discount,
+			COALESCE(SUM(od.final_price), 0) AS gmv,
+			COUNT(DISTINCT c.user_id) AS unique_buyers`
+		fromQ = ` FROM orders o
+			INNER JOIN order_details od ON od.order_id = o.id
+			INNER JOIN carts c ON c.id = o.cart_id
+			WHERE o.voucher_id = ? AND od.order_status <> ?`
+	}
+	if merchantID != nil {
+		fromQ += " AND od.merchant_id = ?"
+		args = append(args, *merchantID)
+	}
+
+	var total model.PromotionReport
+	if err := r.db.WithContext(ctx).Raw("SELECT "+selectQ+fromQ, args...).Scan(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("promotionRepo/GetPromotionReport: %w", err)
+	}
+
+	daily := []model.PromotionReport{}
+	dailyQ := "SELECT DATE(o.order_date) AS date, " + selectQ + fromQ + " GROUP BY DATE(o.order_date) ORDER BY date"
+	if err := r.db.WithContext(ctx).Raw(dailyQ, args...).Scan(&daily).Error; err != nil {
+		return nil, nil, fmt.Errorf("promotionRepo/GetPromotionReport: %w", err)
+	}
+	return &total, daily, nil
+}
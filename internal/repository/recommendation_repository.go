@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"digital-test-vm/be/internal/shared"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationRepo interface {
+	ListRecommendedProducts(ctx context.Context, viewerID *uint64, cursor string, page uint64, limit uint64) ([]model.ListProduct, uint64, string, error)
+	RefreshCoPurchases(ctx context.Context) error
+	ListRelatedProducts(ctx context.Context, productID uint64, limit uint64) ([]model.ListProduct, error)
+}
+
+type recommendationRepo struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepo(db *gorm.DB) RecommendationRepo {
+	return &recommendationRepo{
+		db: db,
+	}
+}
+
+/*
+viewer affinity built from favorites and past purchases, a purchase weighs more than a favorite.
+each row carries the categories and merchant of a product the viewer has shown interest in
+*/
+var viewerAffinityQuery = `WITH viewer_affinity AS (
+	SELECT sp.id AS product_id, sp.merchant_id, sp.category_lv1_id, sp.category_lv2_id, sp.category_lv3_id, s.weight, s.is_purchased
+	FROM (
+		SELECT pf.product_id, 2 AS weight, FALSE AS is_purchased
+			FROM product_favorites pf
+			WHERE pf.user_id = ? AND pf.deleted_at IS NULL
+		UNION ALL
+		SELECT vcp.product_id, 3 AS weight, TRUE AS is_purchased
+			FROM order_detail_products odp
+			INNER JOIN variant_combination_products vcp ON vcp.id = odp.variant_combination_product_id
+			INNER JOIN order_details od ON od.id = odp.order_detail_id
+			INNER JOIN orders o ON o.id = od.order_id
+			INNER JOIN carts c ON c.id = o.cart_id
+			WHERE c.user_id = ? AND od.order_status <> ?
+	) s
+	INNER JOIN products sp ON sp.id = s.product_id
+) `
+
+/* popularity alone is what anonymous viewers are ranked by */
+var productPopularityScore = `(LN(1 + p.total_sold) + p.average_rating)`
+
+/* closer category levels and already trusted merchants count more, popularity breaks ties */
+var productAffinityScore = `(COALESCE((SELECT SUM(va.weight * CASE
+		WHEN va.category_lv3_id = p.category_lv3_id THEN 3
+		WHEN va.category_lv2_id = p.category_lv2_id THEN 2
+		WHEN va.category_lv1_id = p.category_lv1_id THEN 1
+		ELSE 0 END) FROM viewer_affinity va), 0)
+	+ COALESCE((SELECT SUM(va.weight) FROM viewer_affinity va WHERE va.merchant_id = p.merchant_id), 0) * 0.5
+	+ ` + productPopularityScore + `)`
+
+/* returns the WITH clause, its args and the score to order by for the viewer, anonymous viewers get no WITH clause */
+func recommendationScore(viewerID *uint64) (string, []interface{}, string) {
+	if viewerID == nil {
+		return "", []interface{}{}, productPopularityScore
+	}
+	return viewerAffinityQuery, []interface{}{*viewerID, *viewerID, shared.Canceled.String()}, productAffinityScore
+}
+
+func (r *recommendationRepo) ListRecommendedProducts(ctx context.Context, viewerID *uint64, cursor string, page uint64, limit uint64) ([]model.ListProduct, uint64, string, error) {
+	withQ, withArgs, score := recommendationScore(viewerID)
+
+	where := " WHERE p.is_active = TRUE AND p.total_stock > 0"
+	whereArgs := []interface{}{}
+	if viewerID != nil {
+		/* products already bought or sold by the viewer are not worth recommending */
+		where += ` AND p.id NOT IN (SELECT va.product_id FROM viewer_affinity va WHERE va.is_purchased)
+			AND u.id <> ?`
+		whereArgs = append(whereArgs, *viewerID)
+	}
+
+	products, totalItems, nextCursor, err := pageListProducts(ctx, r.db, listProductPage{
+		withQ:     withQ,
+		withArgs:  withArgs,
+		sortKey:   score,
+		where:     where,
+		whereArgs: whereArgs,
+		sortOrder: shared.DESC.String(),
+		cursor:    cursor,
+		page:      page,
+		limit:     limit,
+	})
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("recommendationRepo/ListRecommendedProducts: %w", err)
+	}
+
+	return products, totalItems, nextCursor, nil
+}
+
+/* two products are co-purchased when they were ordered together in the same order */
+var refreshCoPurchaseQuery = `INSERT INTO product_co_purchases (product_id, related_product_id, frequency)
+	SELECT a.product_id, b.product_id, COUNT(*)
+	FROM order_products a
+	INNER JOIN order_products b
+		ON b.order_id = a.order_id
+		AND b.product_id <> a.product_id
+	GROUP BY a.product_id, b.product_id`
+
+var orderProductsQuery = `WITH order_products AS (
+	SELECT DISTINCT od.order_id, vcp.product_id
+	FROM order_detail_products odp
+	INNER JOIN variant_combination_products vcp ON vcp.id = odp.variant_combination_product_id
+	INNER JOIN order_details od ON od.id = odp.order_detail_id
+	WHERE od.order_status <> ?
+) `
+
+func (r *recommendationRepo) RefreshCoPurchases(ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_co_purchases").Error; err != nil {
+			return err
+		}
+		return tx.Exec(orderProductsQuery+refreshCoPurchaseQuery, shared.Canceled.String()).Error
+	})
+	if err != nil {
+		return fmt.Errorf("recommendationRepo/RefreshCoPurchases: %w", err)
+	}
+	return nil
+}
+
+/* co-purchased products come first, the rest is filled with same lv3 category products from other merchants */
+func (r *recommendationRepo) ListRelatedProducts(ctx context.Context, productID uint64, limit uint64) ([]model.ListProduct, error) {
+	products := []model.ListProduct{}
+
+	q := listProductBaseQuery + `
+	LEFT JOIN product_co_purchases cp
+		ON cp.related_product_id = p.id
+		AND cp.product_id = ?
+	WHERE p.is_active = TRUE AND p.total_stock > 0 AND p.deleted_at IS NULL AND p.id <> ?
+	AND (cp.product_id IS NOT NULL OR (
+		p.category_lv3_id = (SELECT src.category_lv3_id FROM products src WHERE src.id = ?)
+		AND p.merchant_id <> (SELECT src.merchant_id FROM products src WHERE src.id = ?)))
+	ORDER BY COALESCE(cp.frequency, 0) DESC, ` + productPopularityScore + ` DESC
+	LIMIT ?`
+
+	if err := r.db.WithContext(ctx).Raw(q, productID, productID, productID, productID, limit).Scan(&products).Error; err != nil {
+		return nil, fmt.Errorf("recommendationRepo/ListRelatedProducts: %w", err)
+	}
+	return products, nil
+}
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"digital-test-vm/be/internal/shared"
+
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -22,6 +24,15 @@ type Repo struct {
 	CourierRepo         CourierRepo
 	CheckoutRepo        CheckoutRepo
 	PromotionRepo       PromotionRepo
+	FlashSaleRepo       FlashSaleRepo
+	SuggestionRepo      SuggestionRepo
+	RecommendationRepo  RecommendationRepo
+	ProductViewRepo     ProductViewRepo
+	MediaStorage        MediaStorage
+	NotificationRepo    NotificationRepo
+	StockAlertRepo      StockAlertRepo
+	StockMovementRepo   StockMovementRepo
+	ProductQuestionRepo ProductQuestionRepo
 }
 
 func NewRepo(db *gorm.DB, redis *redis.Client) *Repo {
@@ -36,11 +47,20 @@ func NewRepo(db *gorm.DB, redis *redis.Client) *Repo {
 		WalletRepo:          NewWalletRepo(db, redis),
 		ProductPhotoRepo:    NewProductPhotoRepo(db),
 		CourierRepo:         NewCourierRepo(db),
+		FlashSaleRepo:       NewFlashSaleRepo(db),
+		SuggestionRepo:      NewSuggestionRepo(db, redis),
+		RecommendationRepo:  NewRecommendationRepo(db),
+		ProductViewRepo:     NewProductViewRepo(db, redis),
+		MediaStorage:        NewMediaStorage(shared.LoadMediaConfig()),
+		NotificationRepo:    NewNotificationRepo(db),
+		StockAlertRepo:      NewStockAlertRepo(db),
+		StockMovementRepo:   NewStockMovementRepo(db),
+		ProductQuestionRepo: NewProductQuestionRepo(db),
 	}
 	repo.ProductRepo = NewProductRepo(db, repo.MerchantRepo, repo.CategoryRepo, repo.ProductFavoriteRepo, repo.VariantRepo)
 	repo.UserRepo = NewUserRepo(db, redis, repo.CartRepo)
 	repo.TransactionRepo = NewTransactionRepo(db, repo.WalletRepo, redis)
-	repo.CheckoutRepo = NewCheckoutRepo(db, repo.TransactionRepo, repo.WalletRepo)
+	repo.CheckoutRepo = NewCheckoutRepo(db, repo.TransactionRepo, repo.WalletRepo, repo.FlashSaleRepo)
 	repo.OrderRepo = NewOrderRepo(db, repo.TransactionRepo, repo.ProductReviewRepo)
 	repo.PromotionRepo = NewPromotionRepo(db, repo.ProductRepo)
 
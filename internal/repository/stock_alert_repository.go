@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type stockAlertRepo struct {
+	db *gorm.DB
+}
+
+type StockAlertRepo interface {
+	UpdateStockAlert(c context.Context, productId uint64, threshold uint64, autoDeactivate bool) error
+	GetLowStockVariants(c context.Context) ([]model.LowStockVariant, error)
+	MarkLowStockNotified(c context.Context, productIds []uint64) error
+	ClearRecoveredLowStock(c context.Context) error
+	GetOutOfStockProducts(c context.Context) ([]model.StockChangedProduct, error)
+	GetRestockedProducts(c context.Context) ([]model.StockChangedProduct, error)
+	SetAutoDeactivated(c context.Context, productId uint64, deactivated bool) error
+}
+
+func NewStockAlertRepo(db *gorm.DB) StockAlertRepo {
+	return &stockAlertRepo{db: db}
+}
+
+/* a new threshold is checked from scratch so the merchant is told again when stock is already below it */
+func (r *stockAlertRepo) UpdateStockAlert(c context.Context, productId uint64, threshold uint64, autoDeactivate bool) error {
+	err := r.db.WithContext(c).Model(&model.Product{}).Where("id = ?", productId).Updates(map[string]interface{}{
+		"low_stock_threshold":   threshold,
+		"auto_deactivate":       autoDeactivate,
+		"low_stock_notified_at": nil,
+		"updated_at":            gorm.Expr("NOW()"),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("stockAlertRepo/UpdateStockAlert: %w", err)
+	}
+	return nil
+}
+
+/* only published products that were not reported yet, a product is reported again once all its variants went back above the threshold */
+func (r *stockAlertRepo) GetLowStockVariants(c context.Context) ([]model.LowStockVariant, error) {
+	res := []model.LowStockVariant{}
+	err := r.db.WithContext(c).Raw(`SELECT
+		p.id AS product_id, p.title, p.low_stock_threshold, m.user_id, u.email,
+		CONCAT_WS(' / ', vtp.type_name, vtc.type_name) AS variant_name, vcp.stock
+		FROM variant_combination_products vcp
+		INNER JOIN products p ON p.id = vcp.product_id
+		INNER JOIN merchants m ON m.id = p.merchant_id
+		INNER JOIN users u ON u.id = m.user_id
+		INNER JOIN variant_combinations vc ON vc.id = vcp.variant_combination_id
+		LEFT JOIN variant_types vtp ON vtp.id = vc.variant_type_parent_id
+		LEFT JOIN variant_types vtc ON vtc.id = vc.variant_type_child_id
+		WHERE p.deleted_at IS NULL
+			AND p.is_draft IS FALSE
+			AND p.low_stock_threshold > 0
+			AND p.low_stock_notified_at IS NULL
+			AND vcp.stock <= p.low_stock_threshold
+		ORDER BY p.id, vcp.id`).Scan(&res).Error
+	if err != nil {
+		return []model.LowStockVariant{}, fmt.Errorf("stockAlertRepo/GetLowStockVariants: %w", err)
+	}
+	return res, nil
+}
+
+func (r *stockAlertRepo) MarkLowStockNotified(c context.Context, productIds []uint64) error {
+	if len(productIds) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(c).Model(&model.Product{}).Where("id IN ?", productIds).
+		Update("low_stock_notified_at", gorm.Expr("NOW()")).Error
+	if err != nil {
+		return fmt.Errorf("stockAlertRepo/MarkLowStockNotified: %w", err)
+	}
+	return nil
+}
+
+func (r *stockAlertRepo) ClearRecoveredLowStock(c context.Context) error {
+	err := r.db.WithContext(c).Exec(`UPDATE products p
+		SET low_stock_notified_at = NULL
+		WHERE p.low_stock_notified_at IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM variant_combination_products vcp
+				WHERE vcp.product_id = p.id AND vcp.stock <= p.low_stock_threshold)`).Error
+	if err != nil {
+		return fmt.Errorf("stockAlertRepo/ClearRecoveredLowStock: %w", err)
+	}
+	return nil
+}
+
+var stockChangedProductQuery = `SELECT p.id AS product_id, p.title, m.user_id, u.email
+	FROM products p
+	INNER JOIN merchants m ON m.id = p.merchant_id
+	INNER JOIN users u ON u.id = m.user_id
+	WHERE p.deleted_at IS NULL AND `
+
+func (r *stockAlertRepo) GetOutOfStockProducts(c context.Context) ([]model.StockChangedProduct, error) {
+	res := []model.StockChangedProduct{}
+	err := r.db.WithContext(c).Raw(stockChangedProductQuery +
+		`p.auto_deactivate IS TRUE AND p.is_active IS TRUE AND p.total_stock = 0`).Scan(&res).Error
+	if err != nil {
+		return []model.StockChangedProduct{}, fmt.Errorf("stockAlertRepo/GetOutOfStockProducts: %w", err)
+	}
+	return res, nil
+}
+
+/* only products hidden by the stock job come back, products the merchant deactivated stay hidden */
+func (r *stockAlertRepo) GetRestockedProducts(c context.Context) ([]model.StockChangedProduct, error) {
+	res := []model.StockChangedProduct{}
+	err := r.db.WithContext(c).Raw(stockChangedProductQuery +
+		`p.auto_deactivated_at IS NOT NULL AND p.is_active IS FALSE AND p.total_stock > 0`).Scan(&res).Error
+	if err != nil {
+		return []model.StockChangedProduct{}, fmt.Errorf("stockAlertRepo/GetRestockedProducts: %w", err)
+	}
+	return res, nil
+}
+
+func (r *stockAlertRepo) SetAutoDeactivated(c context.Context, productId uint64, deactivated bool) error {
+	value := gorm.Expr("NULL")
+	if deactivated {
+		value = gorm.Expr("NOW()")
+	}
+	if err := r.db.WithContext(c).Model(&model.Product{}).Where("id = ?", productId).Update("auto_deactivated_at", value).Error; err != nil {
+		return fmt.Errorf("stockAlertRepo/SetAutoDeactivated: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	"digital-test-vm/be/internal/shared"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type stockMovementRepo struct {
+	db *gorm.DB
+}
+
+type StockMovementRepo interface {
+	GetStockMovements(c context.Context, req dto.StockMovementRequest) ([]model.StockMovement, string, error)
+}
+
+func NewStockMovementRepo(db *gorm.DB) StockMovementRepo {
+	return &stockMovementRepo{db: db}
+}
+
+/* newest first, ids only grow so the id of the last movement is enough to find the next page */
+func (r *stockMovementRepo) GetStockMovements(c context.Context, req dto.StockMovementRequest) ([]model.StockMovement, string, error) {
+	res := []model.StockMovement{}
+	query := `SELECT sm.*, CONCAT_WS(' / ', vtp.type_name, vtc.type_name) AS variant_name
+		FROM stock_movements sm
+		LEFT JOIN variant_combination_products vcp ON vcp.id = sm.variant_combination_product_id
+		LEFT JOIN variant_combinations vc ON vc.id = vcp.variant_combination_id
+		LEFT JOIN variant_types vtp ON vtp.id = vc.variant_type_parent_id
+		LEFT JOIN variant_types vtc ON vtc.id = vc.variant_type_child_id
+		WHERE sm.product_id = ?`
+	args := []interface{}{req.ProductId}
+	if req.VariantId != 0 {
+		query += " AND sm.variant_combination_product_id = ?"
+		args = append(args, req.VariantId)
+	}
+	if req.Reason != "" {
+		query += " AND sm.reason = ?"
+		args = append(args, req.Reason)
+	}
+	if req.Cursor != "" {
+		cursor, err := decodePageCursor(req.Cursor)
+		if err != nil {
+			return []model.StockMovement{}, "", fmt.Errorf("stockMovementRepo/GetStockMovements: %w", err)
+		}
+		query += " AND sm.id < ?"
+		args = append(args, cursor.ID)
+	}
+	query += " ORDER BY sm.id DESC LIMIT ?"
+	args = append(args, req.Limit+1)
+
+	if err := r.db.WithContext(c).Raw(query, args...).Scan(&res).Error; err != nil {
+		return []model.StockMovement{}, "", fmt.Errorf("stockMovementRepo/GetStockMovements: %w", err)
+	}
+
+	nextCursor := ""
+	if len(res) > req.Limit {
+		res = res[:req.Limit]
+		nextCursor = pageCursor{ID: res[len(res)-1].ID}.encode()
+	}
+	return res, nextCursor, nil
+}
+
+/* stock of every variant of a product, taken before and after an edit to find out what the edit changed */
+func variantStocks(tx *gorm.DB, productId uint64) (map[uint64]uint64, error) {
+	rows := []model.VariantCombinationProduct{}
+	if err := tx.Model(&model.VariantCombinationProduct{}).Select("id", "stock").Where("product_id = ?", productId).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	stocks := map[uint64]uint64{}
+	for _, row := range rows {
+		stocks[row.ID] = row.Stock
+	}
+	return stocks, nil
+}
+
+/* records the edit of a merchant, stock that went up is a restock and stock that went down or disappeared with its variant is an adjustment */
+func recordStockEdit(tx *gorm.DB, productId uint64, before map[uint64]uint64, after map[uint64]uint64) error {
+	movements := []model.StockMovement{}
+	for id, stock := range after {
+		if change := int64(stock) - int64(before[id]); change != 0 {
+			movements = append(movements, newStockMovement(id, productId, change, stock, nil))
+		}
+	}
+	for id, stock := range before {
+		if _, exist := after[id]; !exist && stock > 0 {
+			movements = append(movements, newStockMovement(id, productId, -int64(stock), 0, nil))
+		}
+	}
+	if len(movements) == 0 {
+		return nil
+	}
+	return tx.Create(&movements).Error
+}
+
+func newStockMovement(variantId uint64, productId uint64, change int64, stockAfter uint64, referenceId *uint64) model.StockMovement {
+	reason := shared.StockAdjust
+	if change > 0 {
+		reason = shared.StockRestock
+	}
+	return model.StockMovement{
+		VariantCombinationProductId: variantId,
+		ProductId:                   productId,
+		Quantity:                    change,
+		StockAfter:                  stockAfter,
+		Reason:                      reason.String(),
+		ReferenceId:                 referenceId,
+	}
+}
+
+/*
+moves the stock of a variant and the total stock of its product by change and records it in the ledger, the
+stock after the change is read back from the variant so concurrent changes are recorded in the order they happened
+*/
+func moveVariantStock(tx *gorm.DB, variantId uint64, change int64, reason shared.StockMovementReason, referenceId *uint64) error {
+	variant := model.VariantCombinationProduct{}
+	err := tx.Raw(`UPDATE variant_combination_products
+		SET stock = stock + ?, updated_at = NOW()
+		WHERE id = ?
+		RETURNING id, product_id, stock`, change, variantId).Scan(&variant).Error
+	if err != nil {
+		return err
+	}
+	if variant.ID == 0 {
+		return ErrVariantNotFound
+	}
+	if err := tx.Exec("UPDATE products SET total_stock = total_stock + ? WHERE id = ?", change, variant.ProductId).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.StockMovement{
+		VariantCombinationProductId: variant.ID,
+		ProductId:                   variant.ProductId,
+		Quantity:                    change,
+		StockAfter:                  variant.Stock,
+		Reason:                      reason.String(),
+		ReferenceId:                 referenceId,
+	}).Error
+}
@@ -0,0 +1,328 @@
+package repo
+
+import (
+	"context"
+	"digital-test-vm/be/internal/model"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	suggestionKeyPrefix       = "suggest:"
+	suggestionTitleKey        = "suggest-titles"
+	suggestionBuiltKey        = "suggest-built"
+	suggestionMaxPrefixLength = 20
+	suggestionKindProduct     = "product"
+	suggestionKindCategory    = "category"
+	suggestionKindMerchant    = "merchant"
+)
+
+type SuggestionRepo interface {
+	IndexProduct(ctx context.Context, productID uint64) error
+	RebuildIndex(ctx context.Context) error
+	EnsureIndex(ctx context.Context) error
+	Suggest(ctx context.Context, prefix string, limit int64) (*model.ProductSuggestion, error)
+}
+
+type suggestionRepo struct {
+	db    *gorm.DB
+	redis *redis.Client
+}
+
+func NewSuggestionRepo(db *gorm.DB, redis *redis.Client) SuggestionRepo {
+	return &suggestionRepo{
+		db:    db,
+		redis: redis,
+	}
+}
+
+type suggestionProduct struct {
+	ID           uint64 `gorm:"column:id"`
+	Title        string `gorm:"column:title"`
+	TotalSold    uint64 `gorm:"column:total_sold"`
+	MerchantID   uint64 `gorm:"column:merchant_id"`
+	CategoryLv1  string `gorm:"column:category_lv1_id"`
+	CategoryLv2  string `gorm:"column:category_lv2_id"`
+	CategoryLv3  string `gorm:"column:category_lv3_id"`
+	IsSearchable bool   `gorm:"column:is_searchable"`
+}
+
+type suggestionScore struct {
+	Name  string  `gorm:"column:name"`
+	Score float64 `gorm:"column:score"`
+}
+
+func (r *suggestionRepo) IndexProduct(ctx context.Context, productID uint64) error {
+	products, err := r.suggestionProducts(ctx, &productID)
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/IndexProduct: %w", err)
+	}
+	if len(products) == 0 {
+		return fmt.Errorf("suggestionRepo/IndexProduct: %w", gorm.ErrRecordNotFound)
+	}
+	product := products[0]
+
+	merchants, err := r.merchantScores(ctx, &product.MerchantID)
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/IndexProduct: %w", err)
+	}
+	categories := []suggestionScore{}
+	for lv, id := range []string{product.CategoryLv1, product.CategoryLv2, product.CategoryLv3} {
+		if id == "" {
+			continue
+		}
+		scores, err := r.categoryScores(ctx, lv+1, &id)
+		if err != nil {
+			return fmt.Errorf("suggestionRepo/IndexProduct: %w", err)
+		}
+		categories = append(categories, scores...)
+	}
+
+	/* the previous title has to be dropped from its prefixes when it was renamed or the product is no longer listed */
+	oldTitle, err := r.redis.HGet(ctx, suggestionTitleKey, fmt.Sprint(product.ID)).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("suggestionRepo/IndexProduct: %w", err)
+	}
+
+	_, err = r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if oldTitle != "" && (oldTitle != product.Title || !product.IsSearchable) {
+			for _, prefix := range suggestionPrefixes(oldTitle) {
+				pipe.ZRem(ctx, suggestionKey(suggestionKindProduct, prefix), oldTitle)
+			}
+			pipe.HDel(ctx, suggestionTitleKey, fmt.Sprint(product.ID))
+		}
+		if product.IsSearchable {
+			addSuggestion(ctx, pipe, suggestionKindProduct, suggestionScore{Name: product.Title, Score: float64(product.TotalSold)})
+			pipe.HSet(ctx, suggestionTitleKey, fmt.Sprint(product.ID), product.Title)
+		}
+		for _, m := range merchants {
+			addSuggestion(ctx, pipe, suggestionKindMerchant, m)
+		}
+		for _, c := range categories {
+			addSuggestion(ctx, pipe, suggestionKindCategory, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/IndexProduct: %w", err)
+	}
+	return nil
+}
+
+func (r *suggestionRepo) RebuildIndex(ctx context.Context) error {
+	products, err := r.suggestionProducts(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/RebuildIndex: %w", err)
+	}
+	merchants, err := r.merchantScores(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/RebuildIndex: %w", err)
+	}
+	categories := []suggestionScore{}
+	for lv := 1; lv <= 3; lv++ {
+		scores, err := r.categoryScores(ctx, lv, nil)
+		if err != nil {
+			return fmt.Errorf("suggestionRepo/RebuildIndex: %w", err)
+		}
+		categories = append(categories, scores...)
+	}
+
+	keys := []string{suggestionTitleKey}
+	iter := r.redis.Scan(ctx, 0, suggestionKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("suggestionRepo/RebuildIndex: %w", err)
+	}
+
+	_, err = r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		for _, p := range products {
+			if !p.IsSearchable {
+				continue
+			}
+			addSuggestion(ctx, pipe, suggestionKindProduct, suggestionScore{Name: p.Title, Score: float64(p.TotalSold)})
+			pipe.HSet(ctx, suggestionTitleKey, fmt.Sprint(p.ID), p.Title)
+		}
+		for _, m := range merchants {
+			addSuggestion(ctx, pipe, suggestionKindMerchant, m)
+		}
+		for _, c := range categories {
+			addSuggestion(ctx, pipe, suggestionKindCategory, c)
+		}
+		pipe.Set(ctx, suggestionBuiltKey, 1, 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/RebuildIndex: %w", err)
+	}
+	return nil
+}
+
+/*
+products indexed one by one do not make a full index, so only a finished rebuild marks it as built. an index
+that was never built or was lost with redis is rebuilt instead of waiting for the nightly job
+*/
+func (r *suggestionRepo) EnsureIndex(ctx context.Context) error {
+	built, err := r.redis.Exists(ctx, suggestionBuiltKey).Result()
+	if err != nil {
+		return fmt.Errorf("suggestionRepo/EnsureIndex: %w", err)
+	}
+	if built > 0 {
+		return nil
+	}
+	if err := r.RebuildIndex(ctx); err != nil {
+		return fmt.Errorf("suggestionRepo/EnsureIndex: %w", err)
+	}
+	return nil
+}
+
+func (r *suggestionRepo) Suggest(ctx context.Context, prefix string, limit int64) (*model.ProductSuggestion, error) {
+	res := &model.ProductSuggestion{
+		Products:   []string{},
+		Categories: []string{},
+		Merchants:  []string{},
+	}
+
+	prefix = normalizeSuggestion(prefix)
+	if prefix == "" {
+		return res, nil
+	}
+
+	/* only the first suggestionMaxPrefixLength characters are indexed, longer prefixes are filtered after the lookup */
+	runes := []rune(prefix)
+	fetch := limit
+	if len(runes) > suggestionMaxPrefixLength {
+		runes = runes[:suggestionMaxPrefixLength]
+		fetch = limit * 5
+	}
+
+	for _, kind := range []string{suggestionKindProduct, suggestionKindCategory, suggestionKindMerchant} {
+		members, err := r.redis.ZRevRange(ctx, suggestionKey(kind, string(runes)), 0, fetch-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("suggestionRepo/Suggest: %w", err)
+		}
+
+		matches := []string{}
+		for _, m := range members {
+			if int64(len(matches)) == limit {
+				break
+			}
+			if hasWordPrefix(normalizeSuggestion(m), prefix) {
+				matches = append(matches, m)
+			}
+		}
+
+		switch kind {
+		case suggestionKindProduct:
+			res.Products = matches
+		case suggestionKindCategory:
+			res.Categories = matches
+		case suggestionKindMerchant:
+			res.Merchants = matches
+		}
+	}
+	return res, nil
+}
+
+func (r *suggestionRepo) suggestionProducts(ctx context.Context, productID *uint64) ([]suggestionProduct, error) {
+	products := []suggestionProduct{}
+	q := r.db.WithContext(ctx).Table("products p").
+		Select(`p.id, p.title, p.total_sold, p.merchant_id,
+			COALESCE(p.category_lv1_id, '') AS category_lv1_id,
+			COALESCE(p.category_lv2_id, '') AS category_lv2_id,
+			COALESCE(p.category_lv3_id, '') AS category_lv3_id,
+			(p.is_active AND p.deleted_at IS NULL) AS is_searchable`)
+	if productID != nil {
+		q = q.Where("p.id = ?", *productID)
+	} else {
+		q = q.Where("p.is_active AND p.deleted_at IS NULL")
+	}
+	if err := q.Scan(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+/* merchants are weighted by the total sold of their listed products */
+func (r *suggestionRepo) merchantScores(ctx context.Context, merchantID *uint64) ([]suggestionScore, error) {
+	scores := []suggestionScore{}
+	q := r.db.WithContext(ctx).Table("merchants m").
+		Select("m.name, COALESCE(SUM(p.total_sold), 0) AS score").
+		Joins("LEFT JOIN products p ON p.merchant_id = m.id AND p.is_active AND p.deleted_at IS NULL").
+		Where("m.deleted_at IS NULL").
+		Group("m.id, m.name")
+	if merchantID != nil {
+		q = q.Where("m.id = ?", *merchantID)
+	}
+	if err := q.Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+/* categories are weighted by the total sold of the listed products under them */
+func (r *suggestionRepo) categoryScores(ctx context.Context, level int, categoryID *string) ([]suggestionScore, error) {
+	scores := []suggestionScore{}
+	q := r.db.WithContext(ctx).Table(fmt.Sprintf("categories_lv%d c", level)).
+		Select("c.name, COALESCE(SUM(p.total_sold), 0) AS score").
+		Joins(fmt.Sprintf("LEFT JOIN products p ON p.category_lv%d_id = c.id AND p.is_active AND p.deleted_at IS NULL", level)).
+		Group("c.id, c.name")
+	if categoryID != nil {
+		q = q.Where("c.id = ?", *categoryID)
+	}
+	if err := q.Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+/* scores only move up so the same name shared by several rows keeps its best weight */
+func addSuggestion(ctx context.Context, pipe redis.Pipeliner, kind string, s suggestionScore) {
+	if normalizeSuggestion(s.Name) == "" {
+		return
+	}
+	for _, prefix := range suggestionPrefixes(s.Name) {
+		pipe.ZAddGT(ctx, suggestionKey(kind, prefix), redis.Z{Score: s.Score, Member: s.Name})
+	}
+}
+
+func suggestionKey(kind string, prefix string) string {
+	return suggestionKeyPrefix + kind + ":" + prefix
+}
+
+func normalizeSuggestion(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+/* every prefix starting at each word, so "kaos polos" is found by "ka" and "pol" */
+func suggestionPrefixes(text string) []string {
+	words := strings.Fields(normalizeSuggestion(text))
+	seen := make(map[string]bool)
+	prefixes := []string{}
+	for i := range words {
+		rest := []rune(strings.Join(words[i:], " "))
+		for l := 1; l <= len(rest) && l <= suggestionMaxPrefixLength; l++ {
+			prefix := string(rest[:l])
+			if !seen[prefix] {
+				seen[prefix] = true
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+func hasWordPrefix(text string, prefix string) bool {
+	words := strings.Fields(text)
+	for i := range words {
+		if strings.HasPrefix(strings.Join(words[i:], " "), prefix) {
+			return true
+		}
+	}
+	return false
+}
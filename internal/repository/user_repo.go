@@ -76,6 +76,7 @@ func (ur *userRepo) FindUserInfo(ctx context.Context, id uint64) (*dto.UserInfo,
 		PhoneNumber: userModel.PhoneNumber,
 		Email: userModel.Username,
 		IsSeller: userModel.IsSeller,
+		IsAdmin: userModel.IsAdmin,
 		CartId: cart.ID,
 		WalletId: walletId,
 		MerchantId: nil,
@@ -2,10 +2,12 @@ package repo
 
 import (
 	"context"
+	"digital-test-vm/be/internal/dto"
 	"digital-test-vm/be/internal/model"
 	"digital-test-vm/be/internal/shared"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -18,6 +20,7 @@ type VariantRepo interface {
 	GetVariantCombinationsByProductID(c context.Context, tx *gorm.DB, productID uint64) ([]model.VariantCombinationDetailResult, error)
 	GetVariantProduct(c context.Context, productId uint64, merchantId uint64) ([]*model.VariantDetailResult, error)
 	IsVariantCombinationProductIsExists(c context.Context, id uint64) (*model.VariantCombinationProduct, bool)
+	UpdateVariants(c context.Context, merchantID uint64, variants []dto.UpdateVariant) error
 }
 
 func NewVariantRepo(db *gorm.DB) VariantRepo {
@@ -26,8 +29,13 @@ func NewVariantRepo(db *gorm.DB) VariantRepo {
 
 func (r *variantRepo) GetVariantProduct(c context.Context, productId, merchantId uint64) ([]*model.VariantDetailResult, error) {
 	res := []*model.VariantDetailResult{}
-	err := r.db.WithContext(c).Raw(`select vcp.id as variant_combination_product_id,vc.id as variant_combination_id, vt.type_name as type_name_parent, vtc.type_name as type_name_child, vg.group_name as group_name_parent, vgc.group_name as group_name_child, vcp.stock, vcp.price, vtc.variant_image as variant_image from products as p
+	err := r.db.WithContext(c).Raw(`select vcp.id as variant_combination_product_id,vc.id as variant_combination_id, vt.type_name as type_name_parent, vtc.type_name as type_name_child, vg.group_name as group_name_parent, vgc.group_name as group_name_child, vcp.stock, vcp.price, vtc.variant_image as variant_image,
+	fsv.sale_price, fsv.sale_stock, fsv.end_date as sale_end_date from products as p
 	inner join variant_combination_products as vcp on p.id=vcp.product_id
+	left join (select fsp.variant_combination_product_id, fsp.sale_price, fsp.sale_stock - fsp.sold as sale_stock, fs.end_date
+		from flash_sale_products as fsp
+		inner join flash_sales as fs on fs.id = fsp.flash_sale_id and fs.deleted_at is null and fs.start_date <= now() and fs.end_date >= now()
+		where fsp.sold < fsp.sale_stock) as fsv on fsv.variant_combination_product_id = vcp.id
 	inner join variant_combinations as vc on vcp.variant_combination_id =vc.id
 	left join variant_types as vt on vt.id = vc.variant_type_parent_id
 	left join variant_types as vtc on vtc.id =vc.variant_type_child_id
@@ -46,9 +54,17 @@ func (r *variantRepo) GetVariantProduct(c context.Context, productId, merchantId
 	return res, nil
 }
 
+/* a variant can only go into a cart while its product is still on sale */
+const purchasableProductCondition = "p.deleted_at IS NULL AND p.is_draft IS FALSE AND p.is_active"
+
 func (r *variantRepo) IsVariantCombinationProductIsExists(c context.Context, id uint64) (*model.VariantCombinationProduct, bool) {
 	var res *model.VariantCombinationProduct
-	if err := r.db.WithContext(c).Model(&model.VariantCombinationProduct{}).Where("id=?", id).First(&res).Error; err != nil {
+	if err := r.db.WithContext(c).Model(&model.VariantCombinationProduct{}).
+		Select("variant_combination_products.*").
+		Joins("INNER JOIN products p ON p.id = variant_combination_products.product_id").
+		Where("variant_combination_products.id = ?", id).
+		Where(purchasableProductCondition).
+		First(&res).Error; err != nil {
 		return nil, false
 	}
 	return res, true
@@ -88,3 +104,85 @@ func (r *variantRepo) GetVariantCombinationsByProductID(c context.Context, tx *g
 	}
 	return res, nil
 }
+
+type lockedVariant struct {
+	ID         uint64 `gorm:"column:id"`
+	ProductID  uint64 `gorm:"column:product_id"`
+	MerchantID uint64 `gorm:"column:merchant_id"`
+	Stock      uint64 `gorm:"column:stock"`
+}
+
+/*
+all variants are changed in one transaction or none of them. min_price is not stored,
+the listings take it from the variant prices so only total_stock has to be recalculated
+*/
+func (r *variantRepo) UpdateVariants(c context.Context, merchantID uint64, variants []dto.UpdateVariant) error {
+	ids := []uint64{}
+	for _, v := range variants {
+		ids = append(ids, v.ID)
+	}
+
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		locked := []lockedVariant{}
+		err := tx.Raw(`SELECT vcp.id, vcp.product_id, p.merchant_id, vcp.stock
+			FROM variant_combination_products vcp
+			INNER JOIN products p ON p.id = vcp.product_id
+			WHERE vcp.id IN ?
+			FOR UPDATE OF vcp`, ids).Scan(&locked).Error
+		if err != nil {
+			return err
+		}
+		if len(locked) != len(ids) {
+			return ErrVariantNotFound
+		}
+
+		productIDs := []uint64{}
+		for _, l := range locked {
+			if l.MerchantID != merchantID {
+				return ErrVariantNotOwned
+			}
+			if _, exist := checkValueIDIn(l.ProductID, productIDs...); !exist {
+				productIDs = append(productIDs, l.ProductID)
+			}
+		}
+
+		lockedByID := map[uint64]lockedVariant{}
+		for _, l := range locked {
+			lockedByID[l.ID] = l
+		}
+
+		movements := []model.StockMovement{}
+		for _, v := range variants {
+			if v.Stock != nil && *v.Stock != lockedByID[v.ID].Stock {
+				change := int64(*v.Stock) - int64(lockedByID[v.ID].Stock)
+				movements = append(movements, newStockMovement(v.ID, lockedByID[v.ID].ProductID, change, *v.Stock, nil))
+			}
+
+			updates := map[string]interface{}{"updated_at": time.Now()}
+			if v.Stock != nil {
+				updates["stock"] = *v.Stock
+			}
+			if v.Price != nil {
+				updates["price"] = *v.Price
+			}
+			if err := tx.Model(&model.VariantCombinationProduct{}).Where("id = ?", v.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(movements) > 0 {
+			if err := tx.Create(&movements).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`UPDATE products p
+			SET total_stock = (SELECT COALESCE(SUM(vcp.stock), 0) FROM variant_combination_products vcp WHERE vcp.product_id = p.id),
+			updated_at = NOW()
+			WHERE p.id IN ?`, productIDs).Error
+	})
+	if err != nil {
+		return fmt.Errorf("variantRepo/UpdateVariants: %w", err)
+	}
+	return nil
+}
@@ -21,6 +21,16 @@ func (s *Server) InitRouter() {
 	})
 
 	r.GET("/ping", s.Handler.Ping.PingHandler)
+
+	/* uploads kept on the local disk are served by the api itself */
+	if media := shared.LoadMediaConfig(); media.Driver == shared.MediaDriverLocal {
+		r.Static(media.LocalPath, media.LocalDir)
+	}
+	uploads := r.Group("/uploads", middleware.AuthMiddleware())
+	{
+		uploads.POST("", s.Handler.MediaHandler.UploadHandler)
+	}
+
 	likeProduct := r.Group("/like-product", middleware.AuthMiddleware())
 	{
 		likeProduct.POST("", s.Handler.ProductFavoriteHandler.LikeProduct)
@@ -99,18 +109,46 @@ func (s *Server) InitRouter() {
 	//products
 	products := r.Group("/products")
 	{
-		products.GET("/:id", s.Handler.ProductHandler.ProductDetail)
-		products.GET("", s.Handler.ProductHandler.GetProducts)
-		products.GET("/:id/reviews", s.Handler.ProductReviewHandler.GetProductReview)
+		products.GET("/suggest", s.Handler.ProductHandler.SuggestProducts)
+		products.GET("/:id", middleware.OptionalAuthMiddleware(), s.Handler.ProductHandler.ProductDetail)
+		products.GET("", middleware.OptionalAuthMiddleware(), s.Handler.ProductHandler.GetProducts)
+		products.GET("/for-you", middleware.OptionalAuthMiddleware(), s.Handler.ProductHandler.ForYouProducts)
+		products.GET("/:id/reviews", middleware.OptionalAuthMiddleware(), s.Handler.ProductReviewHandler.GetProductReview)
+		products.GET("/:id/related", s.Handler.ProductHandler.RelatedProducts)
 		products.POST("/reviews", middleware.AuthMiddleware(), s.Handler.ProductReviewHandler.CreateProductReview)
 		products.GET("/is-review", middleware.AuthMiddleware(), s.Handler.ProductReviewHandler.IsReviewed)
+		products.GET("/:id/questions", s.Handler.ProductQuestionHandler.GetProductQuestions)
+		products.POST("/:id/questions", middleware.AuthMiddleware(), s.Handler.ProductQuestionHandler.CreateProductQuestion)
 	}
 
 	productsAuth := r.Group("/products", middleware.AuthMiddleware())
 	{
 		productsAuth.POST("", s.Handler.ProductHandler.CreateProductHandler)
+		productsAuth.POST("/import", s.Handler.ProductHandler.ImportProductsHandler)
+		productsAuth.GET("/export", s.Handler.ProductHandler.ExportProductsHandler)
+		productsAuth.PATCH("/variants", s.Handler.ProductHandler.UpdateVariantsHandler)
+		productsAuth.GET("/recently-viewed", s.Handler.ProductHandler.RecentlyViewedProducts)
+		productsAuth.GET("/drafts", s.Handler.ProductHandler.DraftProductsHandler)
+		productsAuth.GET("/archived", s.Handler.ProductHandler.ArchivedProductsHandler)
+		productsAuth.GET("/questions/unanswered", s.Handler.ProductQuestionHandler.GetUnansweredQuestions)
+		productsAuth.PUT("/questions/:id/answer", s.Handler.ProductQuestionHandler.AnswerProductQuestion)
 		productsAuth.GET("/:id/edit", s.Handler.ProductHandler.EditProductHandler)
 		productsAuth.PUT("/:id/update", s.Handler.ProductHandler.UpdateProductHandler)
+		productsAuth.PUT("/:id/stock-alert", s.Handler.ProductHandler.UpdateStockAlertHandler)
+		productsAuth.GET("/:id/stock-history", s.Handler.ProductHandler.StockHistoryHandler)
+		productsAuth.DELETE("/:id", s.Handler.ProductHandler.DeleteProductHandler)
+		productsAuth.POST("/:id/restore", s.Handler.ProductHandler.RestoreProductHandler)
+	}
+
+	reviews := r.Group("/reviews", middleware.AuthMiddleware())
+	{
+		reviews.GET("/merchant", s.Handler.ProductReviewHandler.GetMerchantReviews)
+		reviews.PUT("/:id", s.Handler.ProductReviewHandler.UpdateProductReview)
+		reviews.DELETE("/:id", s.Handler.ProductReviewHandler.DeleteProductReview)
+		reviews.POST("/:id/reply", s.Handler.ProductReviewHandler.ReplyReview)
+		reviews.PUT("/:id/reply", s.Handler.ProductReviewHandler.EditReviewReply)
+		reviews.POST("/:id/helpful", s.Handler.ProductReviewHandler.MarkReviewHelpful)
+		reviews.DELETE("/:id/helpful", s.Handler.ProductReviewHandler.UnmarkReviewHelpful)
 	}
 
 	//activate products
@@ -120,6 +158,13 @@ func (s *Server) InitRouter() {
 		activateProduct.POST("", s.Handler.ProductHandler.ActivateProduct)
 	}
 
+	notifications := r.Group("/notifications", middleware.AuthMiddleware())
+	{
+		notifications.GET("", s.Handler.NotificationHandler.GetNotifications)
+		notifications.PATCH("/read", s.Handler.NotificationHandler.MarkAllNotificationsRead)
+		notifications.PATCH("/:id/read", s.Handler.NotificationHandler.MarkNotificationRead)
+	}
+
 	wallets := r.Group("/wallet", middleware.AuthMiddleware())
 	{
 		wallets.POST("/set-up", s.Handler.WalletHandler.RegisterWalletHandler)
@@ -154,6 +199,7 @@ func (s *Server) InitRouter() {
 		order.PUT("/status/on-delivery", s.Handler.OrderHandler.ChangeOrderStatusToOnDelivery)
 		order.PUT("/status/completed", s.Handler.OrderHandler.ChangeOrderStatusToCompleted)
 		order.PUT("/status/reviewed", s.Handler.OrderHandler.ChangeOrderStatusToReviewed)
+		order.PUT("/status/canceled", s.Handler.OrderHandler.ChangeOrderStatusToCanceled)
 	}
 
 	r.PUT("orders/status/delivered", s.Handler.OrderHandler.ChangeOrderStatusToDelivered)
@@ -162,5 +208,16 @@ func (s *Server) InitRouter() {
 	promotionsAuth := r.Group("/promotions", middleware.AuthMiddleware())
 	{
 		promotionsAuth.POST("", s.Handler.PromotionHandler.CreateMerchantPromotion)
+		promotionsAuth.POST("/flash-sales", s.Handler.PromotionHandler.CreateFlashSale)
+		promotionsAuth.GET("/:id/report", s.Handler.PromotionHandler.GetPromotionReport)
+	}
+	r.GET("/promotions/global", s.Handler.PromotionHandler.ListOngoingGlobalPromotions)
+
+	//admin
+	admin := r.Group("/admin", middleware.AuthMiddleware())
+	{
+		admin.GET("/promotions", s.Handler.PromotionHandler.ListGlobalPromotions)
+		admin.POST("/promotions", s.Handler.PromotionHandler.CreateGlobalPromotion)
+		admin.PUT("/promotions/:id", s.Handler.PromotionHandler.UpdateGlobalPromotion)
 	}
 }
@@ -1,7 +1,9 @@
 package shared
 
 import (
+	"errors"
 	"os"
+	"strings"
 )
 
 type Config struct {
@@ -30,3 +32,58 @@ func LoadConfig() *Config {
 		DbUrl:           os.Getenv("DB_URL"),
 	}
 }
+
+const (
+	MediaDriverLocal = "local"
+	MediaDriverS3    = "s3"
+)
+
+type MediaConfig struct {
+	Driver      string
+	LocalDir    string
+	LocalPath   string
+	BaseURL     string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+/* local files are served by the api under LocalPath, s3 objects are read from BaseURL (the public bucket url) */
+func LoadMediaConfig() *MediaConfig {
+	cfg := &MediaConfig{
+		Driver:      os.Getenv("MEDIA_DRIVER"),
+		LocalDir:    os.Getenv("MEDIA_LOCAL_DIR"),
+		LocalPath:   "/media",
+		BaseURL:     strings.TrimSuffix(os.Getenv("MEDIA_BASE_URL"), "/"),
+		S3Endpoint:  strings.TrimSuffix(os.Getenv("MEDIA_S3_ENDPOINT"), "/"),
+		S3Region:    os.Getenv("MEDIA_S3_REGION"),
+		S3Bucket:    os.Getenv("MEDIA_S3_BUCKET"),
+		S3AccessKey: os.Getenv("MEDIA_S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("MEDIA_S3_SECRET_KEY"),
+	}
+	if cfg.Driver != MediaDriverS3 {
+		cfg.Driver = MediaDriverLocal
+	}
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "./uploads"
+	}
+	if cfg.S3Region == "" {
+		cfg.S3Region = "us-east-1"
+	}
+	if cfg.BaseURL == "" && cfg.Driver == MediaDriverS3 {
+		cfg.BaseURL = cfg.S3Endpoint + "/" + cfg.S3Bucket
+	}
+	return cfg
+}
+
+var ErrMediaBaseURLRequired = errors.New("MEDIA_BASE_URL is required when MEDIA_DRIVER is local")
+
+/* a local upload url is handed out to clients, so it can not be guessed from the host the api runs on */
+func (c *MediaConfig) Validate() error {
+	if c.Driver == MediaDriverLocal && c.BaseURL == "" {
+		return ErrMediaBaseURLRequired
+	}
+	return nil
+}
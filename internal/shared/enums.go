@@ -8,6 +8,8 @@ const (
 	Price
 	Date
 	Recommended
+	Relevance
+	Trending
 )
 
 const DateFormat = "02/01/2006"
@@ -24,6 +26,10 @@ func (psc ProductsSortCriteria) String() string {
 		return "date"
 	case Recommended:
 		return "recommended"
+	case Relevance:
+		return "relevance"
+	case Trending:
+		return "trending"
 	}
 	return "unknown"
 }
@@ -40,6 +46,10 @@ func (psc ProductsSortCriteria) Translate() string {
 		return "created_at"
 	case Recommended:
 		return "recommended"
+	case Relevance:
+		return "relevance"
+	case Trending:
+		return "view_count"
 	}
 	return "unknown"
 }
@@ -108,6 +118,9 @@ type VoucherType struct {
 
 var Discount VoucherType = NewVoucherType("DISC")
 var Cut VoucherType = NewVoucherType("CUT")
+var FreeShipping VoucherType = NewVoucherType("SHIP")
+var BuyXGetY VoucherType = NewVoucherType("BXGY")
+var Bundle VoucherType = NewVoucherType("BUNDLE")
 
 func NewVoucherType(status string) VoucherType {
 	return VoucherType{
@@ -119,6 +132,12 @@ func (o *VoucherType) String() string {
 	return o.status
 }
 
+// IsBundle reports whether the type is applied automatically over cart lines
+// instead of being redeemed as a voucher.
+func (o *VoucherType) IsBundle() bool {
+	return o.status == BuyXGetY.status || o.status == Bundle.status
+}
+
 const ADMIN_WALLET uint64 = 3
 
 var ADMIN_COURIER map[string]uint64 = map[string]uint64{
@@ -172,3 +191,62 @@ var (
 	PromotionStatusWillCome = NewPromotionStatus("will_come")
 	PromotionStatusEnded    = NewPromotionStatus("has_ended")
 )
+
+type NotificationType struct {
+	kind string
+}
+
+func NewNotificationType(kind string) NotificationType {
+	return NotificationType{
+		kind: kind,
+	}
+}
+
+func (o *NotificationType) String() string {
+	return o.kind
+}
+
+var (
+	NotificationLowStock           = NewNotificationType("low_stock")
+	NotificationProductDeactivated = NewNotificationType("product_deactivated")
+	NotificationProductReactivated = NewNotificationType("product_reactivated")
+	NotificationQuestionAnswered   = NewNotificationType("question_answered")
+	NotificationReviewReplied      = NewNotificationType("review_replied")
+)
+
+type ReviewSort struct {
+	sort string
+}
+
+func NewReviewSort(sort string) ReviewSort {
+	return ReviewSort{
+		sort: sort,
+	}
+}
+
+func (o *ReviewSort) String() string {
+	return o.sort
+}
+
+var ReviewSortHelpful = NewReviewSort("helpful")
+
+type StockMovementReason struct {
+	reason string
+}
+
+func NewStockMovementReason(reason string) StockMovementReason {
+	return StockMovementReason{
+		reason: reason,
+	}
+}
+
+func (o *StockMovementReason) String() string {
+	return o.reason
+}
+
+var (
+	StockRestock = NewStockMovementReason("restock")
+	StockSale    = NewStockMovementReason("sale")
+	StockCancel  = NewStockMovementReason("cancel")
+	StockAdjust  = NewStockMovementReason("adjust")
+)
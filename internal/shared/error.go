@@ -19,7 +19,19 @@ var (
 	ErrDecreasedStock          = NewHTTPError(http.StatusBadRequest, "cannot decreased stock")
 	ErrListTransactionNotFound = NewHTTPError(http.StatusBadRequest, "list transaction not found")
 	ErrOrderNotFound           = NewHTTPError(http.StatusBadRequest, "order not found")
-	ErrInvalidAddress           = NewHTTPError(http.StatusBadRequest, "invalid address")
+	ErrInvalidAddress          = NewHTTPError(http.StatusBadRequest, "invalid address")
+	ErrFlashSaleSoldOut        = NewHTTPError(http.StatusBadRequest, "flash sale stock is sold out")
+	ErrFlashSaleLimitExceeded  = NewHTTPError(http.StatusBadRequest, "flash sale purchase limit exceeded")
+	ErrInvalidCursor           = NewHTTPError(http.StatusBadRequest, "invalid page cursor")
+	ErrInvalidProductCSV       = NewHTTPError(http.StatusBadRequest, "csv header does not match the product template")
+	ErrProductCSVTooLong       = NewHTTPError(http.StatusBadRequest, "csv has too many rows")
+	ErrInvalidMediaKind        = NewHTTPError(http.StatusBadRequest, "kind must be product, profile or review")
+	ErrProductIncomplete       = NewHTTPError(http.StatusBadRequest, "a product needs at least one variant before it can be published")
+	ErrProductIsDraft          = NewHTTPError(http.StatusBadRequest, "a draft has to be completed before it can be activated")
+	ErrBlankText               = NewHTTPError(http.StatusBadRequest, "text cannot be blank")
+	ErrReplyNotEditable        = NewHTTPError(http.StatusBadRequest, "a reply can only be edited once within 24 hours of posting it")
+	ErrVoteOwnReview           = NewHTTPError(http.StatusBadRequest, "you cannot vote on your own review")
+	ErrReviewExpired           = NewHTTPError(http.StatusBadRequest, "a review can only be edited within 30 days of posting it")
 
 	/* Error code 401 */
 	ErrUnauthorizedAccess   = NewHTTPError(http.StatusUnauthorized, "you have no authorized to access")
@@ -38,22 +50,35 @@ var (
 	ErrNotHaveAddress    = NewHTTPError(http.StatusForbidden, "please set up an address before regisered as a merchant")
 
 	/* Error code 404 */
-	ErrPageNotFound        = NewHTTPError(http.StatusNotFound, "404 page not found")
-	ErrProductNotFound     = NewHTTPError(http.StatusNotFound, "product not found")
-	ErrMerchantNotFound    = NewHTTPError(http.StatusNotFound, "merchant not found")
-	ErrCategoryNotFound    = NewHTTPError(http.StatusNotFound, "category not found")
-	ErrVariantNotFound     = NewHTTPError(http.StatusNotFound, "product variant not found")
-	ErrUserNotFound        = NewHTTPError(http.StatusNotFound, "user not found")
-	ErrWalletNotFound      = NewHTTPError(http.StatusNotFound, "wallet not found")
-	ErrCartProductNotFound = NewHTTPError(http.StatusNotFound, "cart product not found")
-	ErrPhotoNotFound       = NewHTTPError(http.StatusNotFound, "photo not found")
-	ErrCartEmpty           = NewHTTPError(http.StatusNotFound, "cart is empty")
-	ErrOrderDetailNotFound = NewHTTPError(http.StatusNotFound, "ErrOrderDetailNotFound")
+	ErrPageNotFound         = NewHTTPError(http.StatusNotFound, "404 page not found")
+	ErrProductNotFound      = NewHTTPError(http.StatusNotFound, "product not found")
+	ErrMerchantNotFound     = NewHTTPError(http.StatusNotFound, "merchant not found")
+	ErrCategoryNotFound     = NewHTTPError(http.StatusNotFound, "category not found")
+	ErrVariantNotFound      = NewHTTPError(http.StatusNotFound, "product variant not found")
+	ErrUserNotFound         = NewHTTPError(http.StatusNotFound, "user not found")
+	ErrWalletNotFound       = NewHTTPError(http.StatusNotFound, "wallet not found")
+	ErrCartProductNotFound  = NewHTTPError(http.StatusNotFound, "cart product not found")
+	ErrPhotoNotFound        = NewHTTPError(http.StatusNotFound, "photo not found")
+	ErrCartEmpty            = NewHTTPError(http.StatusNotFound, "cart is empty")
+	ErrOrderDetailNotFound  = NewHTTPError(http.StatusNotFound, "ErrOrderDetailNotFound")
+	ErrPromotionNotFound    = NewHTTPError(http.StatusNotFound, "promotion not found")
+	ErrNotificationNotFound = NewHTTPError(http.StatusNotFound, "notification not found")
+	ErrProductNotArchived   = NewHTTPError(http.StatusNotFound, "product is not in the archive")
+	ErrQuestionNotFound     = NewHTTPError(http.StatusNotFound, "question not found")
+	ErrReviewNotFound       = NewHTTPError(http.StatusNotFound, "review not found")
 
 	/* Error code 409 */
 	ErrAlreadyHaveMerchant  = NewHTTPError(http.StatusConflict, "already have merchant")
 	ErrAlreadyRegistered    = NewHTTPError(http.StatusConflict, "username or email already used")
 	ErrWalletAlreadyCreated = NewHTTPError(http.StatusConflict, "wallet already created")
+	ErrFlashSaleOverlap     = NewHTTPError(http.StatusConflict, "variant already in another flash sale at that time")
+	ErrReviewReplied        = NewHTTPError(http.StatusConflict, "review already has a reply")
+
+	/* Error code 413 */
+	ErrMediaTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "file is too large")
+
+	/* Error code 415 */
+	ErrUnsupportedMediaType = NewHTTPError(http.StatusUnsupportedMediaType, "file must be a jpeg, png or gif image")
 
 	/* Error Code 500 */
 	ErrInternalServerError            = NewHTTPError(http.StatusInternalServerError, "internal server error")
@@ -66,6 +66,10 @@ func (c *checkoutUsecase) CheckPrice(ctx context.Context, req dto.CheckoutReques
 		orders.OrderDetails = append(orders.OrderDetails, orderDetails)
 	}
 
+	if err := c.applyFlashSale(ctx, orders, user); err != nil {
+		return dto.CheckPriceResponse{}, fmt.Errorf("checkoutUsecase/CheckPrice : %w", err)
+	}
+
 	_, check, err := c.calculateFinalPrice(ctx, orders)
 	if err != nil {
 		return dto.CheckPriceResponse{}, fmt.Errorf("checkoutUsecase/CheckPrice : %w", err)
@@ -141,6 +145,10 @@ func (c *checkoutUsecase) CheckoutCart(ctx context.Context, req dto.CheckoutRequ
 		orders.OrderDetails = append(orders.OrderDetails, orderDetails)
 	}
 
+	if err := c.applyFlashSale(ctx, orders, user); err != nil {
+		return fmt.Errorf("checkoutUsecase/CheckoutCart : %w", err)
+	}
+
 	orders, _, err = c.calculateFinalPrice(ctx, orders)
 	if err != nil {
 		return fmt.Errorf("checkoutUsecase/CheckoutCart : %w", err)
@@ -168,21 +176,79 @@ func (c *checkoutUsecase) CheckoutCart(ctx context.Context, req dto.CheckoutRequ
 	return nil
 }
 
+// applyFlashSale replaces the variant price with the flash sale price for
+// variants that are currently on sale, enforcing sale stock and per-user limits.
+func (c *checkoutUsecase) applyFlashSale(ctx context.Context, order *dto.Orders, user dto.UserInfo) error {
+	var variantIds []uint64
+	for _, orderDetail := range order.OrderDetails {
+		for _, product := range orderDetail.OrderDetailProducts {
+			variantIds = append(variantIds, product.VariantCombinationProductId)
+		}
+	}
+
+	flashSales, err := c.repo.FlashSaleRepo.GetActiveFlashSaleVariants(ctx, nil, variantIds)
+	if err != nil {
+		return fmt.Errorf("checkoutUsecase/applyFlashSale : %w", err)
+	}
+
+	for i, orderDetail := range order.OrderDetails {
+		for j, product := range orderDetail.OrderDetailProducts {
+			flashSale, ok := flashSales[product.VariantCombinationProductId]
+			if !ok {
+				continue
+			}
+			if flashSale.RemainingStock() < product.Quantity {
+				return fmt.Errorf("checkoutUsecase/applyFlashSale : %w", repo.ErrFlashSaleSoldOut)
+			}
+			if flashSale.PurchaseLimit > 0 {
+				bought, err := c.repo.FlashSaleRepo.CountUserFlashSalePurchases(ctx, nil, user.ID, flashSale.FlashSaleProductID)
+				if err != nil {
+					return fmt.Errorf("checkoutUsecase/applyFlashSale : %w", err)
+				}
+				if bought+product.Quantity > flashSale.PurchaseLimit {
+					return fmt.Errorf("checkoutUsecase/applyFlashSale : %w", ErrFlashSaleLimitExceeded)
+				}
+			}
+			flashSaleProductId := flashSale.FlashSaleProductID
+			order.OrderDetails[i].OrderDetailProducts[j].Price = flashSale.SalePrice
+			order.OrderDetails[i].OrderDetailProducts[j].FlashSaleProductId = &flashSaleProductId
+		}
+	}
+	return nil
+}
+
 func (c *checkoutUsecase) calculateFinalPrice(ctx context.Context, order *dto.Orders) (*dto.Orders, dto.CheckPriceResponse, error) {
 	check := dto.CheckPriceResponse{}
 
+	var productIds []uint64
+	for _, orderDetail := range order.OrderDetails {
+		for _, orderDetailProduct := range orderDetail.OrderDetailProducts {
+			productIds = append(productIds, orderDetailProduct.ProductId)
+		}
+	}
+	bundles, err := c.repo.CheckoutRepo.GetBundlePromotions(ctx, productIds)
+	if err != nil {
+		return nil, dto.CheckPriceResponse{}, fmt.Errorf("checkoutUsecase/calculateFinalPrice : %w", err)
+	}
+	bundleNames := c.applyBundlePromotions(bundles, order)
+
 	for j, orderDetail := range order.OrderDetails {
 		check.CheckPriceMerchants = append(check.CheckPriceMerchants, dto.CheckPriceMerchant{})
 		for i, orderDetailProduct := range orderDetail.OrderDetailProducts {
 			check.CheckPriceMerchants[j].CheckPriceProducts = append(check.CheckPriceMerchants[j].CheckPriceProducts, dto.CheckPriceProduct{})
 			amount := decimal.NewFromInt(int64(orderDetailProduct.Quantity))
 			orderDetailProduct.InitialPrice = orderDetailProduct.Price.Mul(amount)
-			orderDetailProduct.FinalPrice = orderDetailProduct.InitialPrice
+			orderDetailProduct.FinalPrice = orderDetailProduct.InitialPrice.Sub(orderDetailProduct.BundleSaving)
 			orderDetail.OrderDetailProducts[i] = orderDetailProduct
-			orderDetail.InitialPrice = orderDetail.InitialPrice.Add(orderDetailProduct.InitialPrice)
+			orderDetail.InitialPrice = orderDetail.InitialPrice.Add(orderDetailProduct.FinalPrice)
 			check.CheckPriceMerchants[j].CheckPriceProducts[i].TotalPrice = orderDetailProduct.FinalPrice.String()
 			check.CheckPriceMerchants[j].CheckPriceProducts[i].ProductId = orderDetailProduct.ProductId
 			check.CheckPriceMerchants[j].CheckPriceProducts[i].ProductName = orderDetailProduct.Name
+			if orderDetailProduct.BundlePromotionId != nil {
+				check.CheckPriceMerchants[j].CheckPriceProducts[i].InitialPrice = orderDetailProduct.InitialPrice.String()
+				check.CheckPriceMerchants[j].CheckPriceProducts[i].BundleName = bundleNames[*orderDetailProduct.BundlePromotionId]
+				check.CheckPriceMerchants[j].CheckPriceProducts[i].BundleSaving = orderDetailProduct.BundleSaving.String()
+			}
 		}
 		// orderDetail.InitialPrice = order.InitialPrice.Add(orderDetail.InitialPrice)
 		orderDetail.FinalPrice = orderDetail.InitialPrice
@@ -214,7 +280,117 @@ func (c *checkoutUsecase) calculateFinalPrice(ctx context.Context, order *dto.Or
 	return order, check, nil
 }
 
+// applyBundlePromotions evaluates the merchant bundle promotions over the cart
+// lines and stores the saving on each line. A line takes part in one bundle at
+// most and flash sale lines are excluded. Returns promotion names by id.
+func (c *checkoutUsecase) applyBundlePromotions(bundles []model.BundlePromotionProduct, order *dto.Orders) map[uint64]string {
+	names := make(map[uint64]string)
+	promotions := make(map[uint64][]model.BundlePromotionProduct)
+	var promotionIds []uint64
+	for _, bundle := range bundles {
+		if _, ok := promotions[bundle.PromotionId]; !ok {
+			promotionIds = append(promotionIds, bundle.PromotionId)
+		}
+		promotions[bundle.PromotionId] = append(promotions[bundle.PromotionId], bundle)
+		names[bundle.PromotionId] = bundle.PromoName
+	}
+
+	for j, orderDetail := range order.OrderDetails {
+		for _, promotionId := range promotionIds {
+			rows := promotions[promotionId]
+			if rows[0].MerchantId != orderDetail.MerchantId {
+				continue
+			}
+			switch rows[0].PromotionType {
+			case shared.BuyXGetY.String():
+				c.applyBuyXGetY(promotionId, rows, order.OrderDetails[j].OrderDetailProducts)
+			case shared.Bundle.String():
+				c.applyBundlePrice(promotionId, rows, order.OrderDetails[j].OrderDetailProducts)
+			}
+		}
+	}
+	return names
+}
+
+func (c *checkoutUsecase) isBundleLine(rows []model.BundlePromotionProduct, product dto.OrderDetailProducts) bool {
+	if product.BundlePromotionId != nil || product.FlashSaleProductId != nil {
+		return false
+	}
+	for _, row := range rows {
+		if row.ProductId == product.ProductId {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checkoutUsecase) applyBuyXGetY(promotionId uint64, rows []model.BundlePromotionProduct, products []dto.OrderDetailProducts) {
+	groupSize := rows[0].BuyQuantity + rows[0].GetQuantity
+	if rows[0].BuyQuantity == 0 || rows[0].GetQuantity == 0 {
+		return
+	}
+	for i, product := range products {
+		if !c.isBundleLine(rows, product) {
+			continue
+		}
+		free := (product.Quantity / groupSize) * rows[0].GetQuantity
+		if free == 0 {
+			continue
+		}
+		id := promotionId
+		products[i].BundlePromotionId = &id
+		products[i].BundleSaving = product.Price.Mul(decimal.NewFromInt(int64(free)))
+	}
+}
+
+func (c *checkoutUsecase) applyBundlePrice(promotionId uint64, rows []model.BundlePromotionProduct, products []dto.OrderDetailProducts) {
+	/* one line per bundled product, every product of the bundle must be in the cart */
+	lines := []int{}
+	for _, row := range rows {
+		found := false
+		for i, product := range products {
+			if product.ProductId == row.ProductId && c.isBundleLine(rows, product) {
+				lines = append(lines, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+
+	count := products[lines[0]].Quantity
+	normalPrice := decimal.Zero
+	for _, i := range lines {
+		if products[i].Quantity < count {
+			count = products[i].Quantity
+		}
+		normalPrice = normalPrice.Add(products[i].Price)
+	}
+	bundlePrice := decimal.NewFromFloat(rows[0].Amount)
+	if !bundlePrice.LessThan(normalPrice) {
+		return
+	}
+
+	saving := normalPrice.Sub(bundlePrice).Mul(decimal.NewFromInt(int64(count)))
+	remaining := saving
+	for k, i := range lines {
+		share := saving.Mul(products[i].Price).Div(normalPrice).Floor()
+		if k == len(lines)-1 {
+			share = remaining
+		}
+		id := promotionId
+		products[i].BundlePromotionId = &id
+		products[i].BundleSaving = share
+		remaining = remaining.Sub(share)
+	}
+}
+
 func (c *checkoutUsecase) verifyVoucher(voucher *model.PromotionProduct) error {
+	if voucher.PromotionType == shared.BuyXGetY.String() || voucher.PromotionType == shared.Bundle.String() {
+		return ErrInvalidVoucher
+	}
 	if voucher.StartDate.After(time.Now()) {
 		return ErrInvalidVoucher
 	}
@@ -238,6 +414,7 @@ func (c *checkoutUsecase) applyGlobalVoucher(voucher *model.PromotionProduct, or
 		if discount.GreaterThan(maxDiscount) {
 			discount = maxDiscount
 		}
+		discount = c.distributePlatformDiscount(order, discount)
 		order.FinalPrice = order.InitialPrice.Add(discount.Neg())
 		check.TotalPrice = order.FinalPrice.String()
 		check.CuttedPrice = order.FinalPrice.String()
@@ -248,15 +425,43 @@ func (c *checkoutUsecase) applyGlobalVoucher(voucher *model.PromotionProduct, or
 		if promo.GreaterThan(order.InitialPrice) {
 			return dto.CheckPriceResponse{}, nil, ErrInvalidVoucher
 		}
-		order.FinalPrice = order.InitialPrice.Add(promo.Neg())
+		cut := c.distributePlatformDiscount(order, promo)
+		order.FinalPrice = order.InitialPrice.Add(cut.Neg())
 		check.TotalPrice = order.FinalPrice.String()
 		check.CuttedPrice = order.FinalPrice.String()
 		check.InitialPrice = order.InitialPrice.String()
-		check.CutPrice = promo.Neg().String()
+		check.CutPrice = cut.Neg().String()
 	}
 	return check, order, nil
 }
 
+// distributePlatformDiscount splits a global voucher discount across merchants
+// proportionally to their subtotal. The platform funds this part, so merchants
+// are still paid their full FinalPrice. Returns the discount actually applied.
+func (c *checkoutUsecase) distributePlatformDiscount(order *dto.Orders, discount decimal.Decimal) decimal.Decimal {
+	subtotal := decimal.Zero
+	for _, orderDetail := range order.OrderDetails {
+		subtotal = subtotal.Add(orderDetail.FinalPrice)
+	}
+	if subtotal.IsZero() {
+		return decimal.Zero
+	}
+	if discount.GreaterThan(subtotal) {
+		discount = subtotal
+	}
+
+	remaining := discount
+	for i, orderDetail := range order.OrderDetails {
+		share := discount.Mul(orderDetail.FinalPrice).Div(subtotal).Floor()
+		if i == len(order.OrderDetails)-1 {
+			share = remaining
+		}
+		order.OrderDetails[i].PlatformDiscount = share
+		remaining = remaining.Sub(share)
+	}
+	return discount
+}
+
 func (c *checkoutUsecase) applyMerchantVoucher(voucher *model.PromotionProduct, order *dto.Orders, check dto.CheckPriceResponse) (dto.CheckPriceResponse, *dto.Orders, error) {
 	promo := decimal.NewFromFloat(voucher.Amount)
 	order.FinalPrice = decimal.Zero
@@ -308,29 +513,31 @@ func (c *checkoutUsecase) applyProductVoucher(voucher *model.PromotionProduct, o
 		for j, product := range merchant.OrderDetailProducts {
 			order.OrderDetails[i].OrderDetailProducts[j].FinalPrice = decimal.Zero
 			product.FinalPrice = decimal.Zero
+			/* bundle savings are already taken off the line */
+			basePrice := product.InitialPrice.Sub(product.BundleSaving)
 			if product.ProductId != *voucher.ProductId {
-				product.FinalPrice = product.InitialPrice
+				product.FinalPrice = basePrice
 				merchant.FinalPrice = merchant.FinalPrice.Add(product.FinalPrice)
 				order.OrderDetails[i].OrderDetailProducts[j].FinalPrice = product.FinalPrice
 				continue
 			}
 			if voucher.PromotionType == shared.Discount.String() {
-				discount := product.InitialPrice.Mul(promo)
+				discount := basePrice.Mul(promo)
 				maxDiscount := decimal.NewFromFloat(*voucher.MaxAmount)
 				if discount.GreaterThan(maxDiscount) {
 					discount = maxDiscount
 				}
-				product.FinalPrice = product.InitialPrice.Add(discount.Neg())
+				product.FinalPrice = basePrice.Add(discount.Neg())
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].TotalPrice = product.FinalPrice.String()
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].CuttedPrice = product.FinalPrice.String()
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].InitialPrice = product.InitialPrice.String()
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].Discount = promo.String()
 			}
 			if voucher.PromotionType == shared.Cut.String() {
-				if promo.GreaterThan(product.InitialPrice) {
+				if promo.GreaterThan(basePrice) {
 					return dto.CheckPriceResponse{}, nil, ErrInvalidVoucher
 				}
-				product.FinalPrice = product.InitialPrice.Add(promo.Neg())
+				product.FinalPrice = basePrice.Add(promo.Neg())
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].TotalPrice = product.FinalPrice.String()
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].CuttedPrice = product.FinalPrice.String()
 				check.CheckPriceMerchants[i].CheckPriceProducts[j].InitialPrice = product.InitialPrice.String()
@@ -347,8 +554,59 @@ func (c *checkoutUsecase) applyProductVoucher(voucher *model.PromotionProduct, o
 	return check, order, nil
 }
 
+func (c *checkoutUsecase) applyShippingVoucher(voucher *model.PromotionProduct, order *dto.Orders, check dto.CheckPriceResponse) (dto.CheckPriceResponse, *dto.Orders, error) {
+	promo := decimal.NewFromFloat(voucher.Amount)
+	order.FinalPrice = decimal.Zero
+	for i, merchant := range order.OrderDetails {
+		order.FinalPrice = order.FinalPrice.Add(merchant.FinalPrice).Add(merchant.CourierPrice)
+		if !c.isShippingVoucherEligible(voucher, merchant) {
+			continue
+		}
+		discount := merchant.CourierPrice.Mul(promo)
+		if voucher.MaxAmount != nil {
+			maxDiscount := decimal.NewFromFloat(*voucher.MaxAmount)
+			if discount.GreaterThan(maxDiscount) {
+				discount = maxDiscount
+			}
+		}
+		if discount.GreaterThan(merchant.CourierPrice) {
+			discount = merchant.CourierPrice
+		}
+		order.OrderDetails[i].ShippingDiscount = discount
+		order.OrderDetails[i].ShippingVoucherScope = &voucher.PromotionScope
+		order.FinalPrice = order.FinalPrice.Add(discount.Neg())
+		check.CheckPriceMerchants[i].Ongkir = merchant.CourierPrice.Add(discount.Neg()).String()
+		check.CheckPriceMerchants[i].ShippingDiscount = discount.String()
+	}
+	check.TotalPrice = order.FinalPrice.String()
+	return check, order, nil
+}
+
+func (c *checkoutUsecase) isShippingVoucherEligible(voucher *model.PromotionProduct, merchant dto.OrderDetails) bool {
+	switch voucher.PromotionScope {
+	case shared.GlobalScope.String():
+		return true
+	case shared.MerchantScope.String():
+		return voucher.MerchantId != nil && merchant.MerchantId == *voucher.MerchantId
+	case shared.ProductScope.String():
+		if voucher.ProductId == nil {
+			return false
+		}
+		for _, product := range merchant.OrderDetailProducts {
+			if product.ProductId == *voucher.ProductId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *checkoutUsecase) applyVoucher(voucher *model.PromotionProduct, order *dto.Orders, check dto.CheckPriceResponse) (dto.CheckPriceResponse, *dto.Orders, error) {
 	var err error
+	if voucher.PromotionType == shared.FreeShipping.String() {
+		return c.applyShippingVoucher(voucher, order, check)
+	}
+
 	if voucher.PromotionScope == shared.GlobalScope.String() {
 		check, order, err = c.applyGlobalVoucher(voucher, order, check)
 	}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/shared"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+/* only the lookups calculateFinalPrice makes are faked, any other call panics on the nil interface */
+type fakeCheckoutRepo struct {
+	repo.CheckoutRepo
+	bundles []model.BundlePromotionProduct
+	voucher *model.PromotionProduct
+}
+
+func (f *fakeCheckoutRepo) GetBundlePromotions(ctx context.Context, productIds []uint64) ([]model.BundlePromotionProduct, error) {
+	return f.bundles, nil
+}
+
+func (f *fakeCheckoutRepo) GetPromotionDetail(c context.Context, promoId uint64) (*model.PromotionProduct, error) {
+	return f.voucher, nil
+}
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func line(productId uint64, price string, quantity uint64) dto.OrderDetailProducts {
+	return dto.OrderDetailProducts{ProductId: productId, Price: dec(price), Quantity: quantity}
+}
+
+func bxgy(promotionId, merchantId uint64, buy, get uint64, productIds ...uint64) []model.BundlePromotionProduct {
+	rows := []model.BundlePromotionProduct{}
+	for _, id := range productIds {
+		rows = append(rows, model.BundlePromotionProduct{
+			PromotionId: promotionId, PromoName: "bxgy", PromotionType: shared.BuyXGetY.String(),
+			BuyQuantity: buy, GetQuantity: get, MerchantId: merchantId, ProductId: id,
+		})
+	}
+	return rows
+}
+
+func bundle(promotionId, merchantId uint64, price float64, productIds ...uint64) []model.BundlePromotionProduct {
+	rows := []model.BundlePromotionProduct{}
+	for _, id := range productIds {
+		rows = append(rows, model.BundlePromotionProduct{
+			PromotionId: promotionId, PromoName: "bundle", PromotionType: shared.Bundle.String(),
+			Amount: price, MerchantId: merchantId, ProductId: id,
+		})
+	}
+	return rows
+}
+
+func TestApplyBundlePromotions(t *testing.T) {
+	flashSaleId := uint64(9)
+	tests := []struct {
+		name    string
+		bundles []model.BundlePromotionProduct
+		lines   []dto.OrderDetailProducts
+		savings []string
+	}{
+		{
+			name:    "buy 2 get 1 frees one item per group of three",
+			bundles: bxgy(1, 1, 2, 1, 10),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 7)},
+			savings: []string{"200"},
+		},
+		{
+			name:    "buy 2 get 1 needs a full group",
+			bundles: bxgy(1, 1, 2, 1, 10),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 2)},
+			savings: []string{"0"},
+		},
+		{
+			name:    "bundle price is split over its products by price",
+			bundles: bundle(2, 1, 120, 10, 11),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 2), line(11, "60", 3)},
+			savings: []string{"50", "30"},
+		},
+		{
+			name:    "bundle needs every product in the cart",
+			bundles: bundle(2, 1, 120, 10, 11),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 2)},
+			savings: []string{"0"},
+		},
+		{
+			name:    "bundle price above the normal price is ignored",
+			bundles: bundle(2, 1, 200, 10, 11),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 1), line(11, "60", 1)},
+			savings: []string{"0", "0"},
+		},
+		{
+			name:    "flash sale lines are left out",
+			bundles: bxgy(1, 1, 1, 1, 10),
+			lines: []dto.OrderDetailProducts{
+				{ProductId: 10, Price: dec("100"), Quantity: 4, FlashSaleProductId: &flashSaleId},
+			},
+			savings: []string{"0"},
+		},
+		{
+			name:    "promotions of another merchant are ignored",
+			bundles: bxgy(1, 2, 1, 1, 10),
+			lines:   []dto.OrderDetailProducts{line(10, "100", 4)},
+			savings: []string{"0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &dto.Orders{OrderDetails: []dto.OrderDetails{{MerchantId: 1, OrderDetailProducts: tt.lines}}}
+			(&checkoutUsecase{}).applyBundlePromotions(tt.bundles, order)
+
+			for i, want := range tt.savings {
+				got := order.OrderDetails[0].OrderDetailProducts[i]
+				if !got.BundleSaving.Equal(dec(want)) {
+					t.Errorf("line %d saving = %s, want %s", i, got.BundleSaving, want)
+				}
+				if got.BundleSaving.IsZero() != (got.BundlePromotionId == nil) {
+					t.Errorf("line %d promotion id = %v with saving %s", i, got.BundlePromotionId, got.BundleSaving)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributePlatformDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		subtotals []string
+		discount  string
+		applied   string
+		shares    []string
+	}{
+		{
+			name:      "split by subtotal with the rounding left on the last merchant",
+			subtotals: []string{"300", "100"},
+			discount:  "50",
+			applied:   "50",
+			shares:    []string{"37", "13"},
+		},
+		{
+			name:      "single merchant takes it all",
+			subtotals: []string{"80"},
+			discount:  "30",
+			applied:   "30",
+			shares:    []string{"30"},
+		},
+		{
+			name:      "discount is capped at the subtotal",
+			subtotals: []string{"60", "40"},
+			discount:  "250",
+			applied:   "100",
+			shares:    []string{"60", "40"},
+		},
+		{
+			name:      "nothing to discount",
+			subtotals: []string{"0", "0"},
+			discount:  "10",
+			applied:   "0",
+			shares:    []string{"0", "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &dto.Orders{}
+			for _, s := range tt.subtotals {
+				order.OrderDetails = append(order.OrderDetails, dto.OrderDetails{FinalPrice: dec(s)})
+			}
+
+			applied := (&checkoutUsecase{}).distributePlatformDiscount(order, dec(tt.discount))
+
+			if !applied.Equal(dec(tt.applied)) {
+				t.Errorf("applied = %s, want %s", applied, tt.applied)
+			}
+			for i, want := range tt.shares {
+				if got := order.OrderDetails[i].PlatformDiscount; !got.Equal(dec(want)) {
+					t.Errorf("merchant %d share = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateFinalPrice(t *testing.T) {
+	voucherId := uint64(5)
+	maxAmount := float64(1000)
+	activeVoucher := func(scope shared.VoucherScope, kind shared.VoucherType, amount float64) *model.PromotionProduct {
+		return &model.PromotionProduct{
+			Id: voucherId, PromotionScope: scope.String(), PromotionType: kind.String(), Amount: amount,
+			StartDate: time.Now().Add(-time.Hour), EndDate: time.Now().Add(time.Hour), Quota: 1, MaxAmount: &maxAmount,
+		}
+	}
+	merchantId := uint64(2)
+	merchantVoucher := activeVoucher(shared.MerchantScope, shared.Discount, 0.1)
+	merchantVoucher.MerchantId = &merchantId
+	expiredVoucher := activeVoucher(shared.GlobalScope, shared.Cut, 10)
+	expiredVoucher.EndDate = time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		bundles   []model.BundlePromotionProduct
+		voucher   *model.PromotionProduct
+		final     string
+		merchants []string
+		platform  []string
+		err       error
+	}{
+		{
+			name:      "lines and courier prices add up",
+			final:     "420",
+			merchants: []string{"300", "100"},
+			platform:  []string{"0", "0"},
+		},
+		{
+			name:      "bundle saving comes off the line",
+			bundles:   bxgy(1, 1, 2, 1, 10),
+			final:     "320",
+			merchants: []string{"200", "100"},
+			platform:  []string{"0", "0"},
+		},
+		{
+			name:      "global cut is funded by the platform",
+			voucher:   activeVoucher(shared.GlobalScope, shared.Cut, 50),
+			final:     "370",
+			merchants: []string{"300", "100"},
+			platform:  []string{"37", "13"},
+		},
+		{
+			name:      "merchant discount lowers that merchant only",
+			voucher:   merchantVoucher,
+			final:     "410",
+			merchants: []string{"300", "90"},
+			platform:  []string{"0", "0"},
+		},
+		{
+			name:    "expired voucher is refused",
+			voucher: expiredVoucher,
+			err:     ErrInvalidVoucher,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &dto.Orders{OrderDetails: []dto.OrderDetails{
+				{MerchantId: 1, CourierPrice: dec("15"), OrderDetailProducts: []dto.OrderDetailProducts{line(10, "100", 3)}},
+				{MerchantId: 2, CourierPrice: dec("5"), OrderDetailProducts: []dto.OrderDetailProducts{line(20, "50", 2)}},
+			}}
+			if tt.voucher != nil {
+				order.VoucherId = &voucherId
+			}
+			u := &checkoutUsecase{repo: &repo.Repo{CheckoutRepo: &fakeCheckoutRepo{bundles: tt.bundles, voucher: tt.voucher}}}
+
+			got, check, err := u.calculateFinalPrice(context.Background(), order)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err %v", err)
+			}
+			if !got.FinalPrice.Equal(dec(tt.final)) || check.TotalPrice != dec(tt.final).String() {
+				t.Errorf("final = %s (check %s), want %s", got.FinalPrice, check.TotalPrice, tt.final)
+			}
+			for i := range tt.merchants {
+				detail := got.OrderDetails[i]
+				if !detail.FinalPrice.Equal(dec(tt.merchants[i])) {
+					t.Errorf("merchant %d final = %s, want %s", i, detail.FinalPrice, tt.merchants[i])
+				}
+				if !detail.PlatformDiscount.Equal(dec(tt.platform[i])) {
+					t.Errorf("merchant %d platform discount = %s, want %s", i, detail.PlatformDiscount, tt.platform[i])
+				}
+			}
+		})
+	}
+}
@@ -3,24 +3,41 @@ package usecase
 import (
 	"context"
 	repo "digital-test-vm/be/internal/repository"
-	"digital-test-vm/be/internal/shared"
 	"time"
 
 	"github.com/go-co-op/gocron"
 )
 
 type Cron struct {
-	orderRepo repo.OrderRepo
+	orderRepo          repo.OrderRepo
+	merchantRepo       repo.MerchantRepo
+	productRepo        repo.ProductRepo
+	suggestionRepo     repo.SuggestionRepo
+	recommendationRepo repo.RecommendationRepo
+	productViewRepo    repo.ProductViewRepo
+	stockAlertUsecase  StockAlertUsecase
 }
 
-func New(u repo.OrderRepo) *Cron {
+func New(r *repo.Repo) *Cron {
 	return &Cron{
-		orderRepo: u,
+		orderRepo:          r.OrderRepo,
+		merchantRepo:       r.MerchantRepo,
+		productRepo:        r.ProductRepo,
+		suggestionRepo:     r.SuggestionRepo,
+		recommendationRepo: r.RecommendationRepo,
+		productViewRepo:    r.ProductViewRepo,
+		stockAlertUsecase:  NewStockAlertUsecase(r),
 	}
 }
 
 func (c *Cron) Run() {
 	s := gocron.NewScheduler(time.UTC)
+	/*
+		Suggestion index is built right away when redis has none yet
+	*/
+	go func() {
+		_ = c.suggestionRepo.EnsureIndex(context.Background())
+	}()
 	/*
 		Scheduler will run at 00:01 everyday
 	*/
@@ -30,8 +47,56 @@ func (c *Cron) Run() {
 			return
 		}
 		for _, v := range listOrder {
-			c.orderRepo.ChangeOrderStatus(context.Background(), v.OrderId, shared.Delivered.String())
+			_ = c.orderRepo.AutoConfirmDelivery(context.Background(), v.Id)
 		}
 	})
+	/*
+		Suggestion index is rebuilt at 03:00 everyday so popularity follows total sold
+	*/
+	s.Every(1).Day().At("20:00").Do(func() {
+		_ = c.suggestionRepo.RebuildIndex(context.Background())
+	})
+	/*
+		Co-purchase associations are recomputed at 02:00 everyday
+	*/
+	s.Every(1).Day().At("19:00").Do(func() {
+		_ = c.recommendationRepo.RefreshCoPurchases(context.Background())
+	})
+	/*
+		Merchant ratings are recounted at 00:00 everyday so late and on time deliveries are counted without a new review
+	*/
+	s.Every(1).Day().At("17:00").Do(func() {
+		_ = c.merchantRepo.RefreshMerchantRatings(context.Background())
+	})
+	/*
+		Stock is checked every 30 minutes for low stock alerts and sold out products
+	*/
+	s.Every(30).Minutes().Do(func() {
+		_ = c.stockAlertUsecase.CheckStock(context.Background())
+	})
+	/*
+		Scheduled products are published every minute once their publish time has passed
+	*/
+	s.Every(1).Minute().Do(func() {
+		ids, err := c.productRepo.PublishScheduledProducts(context.Background())
+		if err != nil {
+			return
+		}
+		for _, id := range ids {
+			_ = c.suggestionRepo.IndexProduct(context.Background(), id)
+		}
+	})
+	/*
+		Buffered product views are written every minute
+	*/
+	s.Every(1).Minute().Do(func() {
+		_ = c.productViewRepo.FlushViews(context.Background())
+	})
+	/*
+		Trending views are recounted at 00:30 everyday so old days leave the window without a new view
+	*/
+	s.Every(1).Day().At("17:30").Do(func() {
+		_ = c.productViewRepo.RefreshTrending(context.Background())
+	})
 	s.StartAsync()
 }
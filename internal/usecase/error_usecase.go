@@ -28,6 +28,14 @@ var (
 	ErrFailedGettingCategory             = errors.New("failed getting category")
 	ErrProductVariantStock               = errors.New(shared.ErrProductVariantStock.Message)
 	ErrWrongUserTryingToAccessMerchant   = errors.New("wrong user trying to access merchant")
+	ErrImportVariantGroupsChanged        = errors.New("variant groups of an existing product can not be changed by import")
+	ErrInvalidVariantUpdate              = errors.New("every variant needs a new stock or a price above zero and may only be listed once")
+	ErrProductIncomplete                 = errors.New(shared.ErrProductIncomplete.Message)
+	ErrProductIsDraft                    = errors.New(shared.ErrProductIsDraft.Message)
+	ErrBlankText                         = errors.New(shared.ErrBlankText.Message)
+	ErrInvalidMediaKind                  = errors.New(shared.ErrInvalidMediaKind.Message)
+	ErrMediaTooLarge                     = errors.New(shared.ErrMediaTooLarge.Message)
+	ErrUnsupportedMediaType              = errors.New(shared.ErrUnsupportedMediaType.Message)
 	ErrInvalidVoucher                    = errors.New("invalid voucher")
 	ErrInsufficientBalance               = errors.New("insufficient balance")
 	ErrCartEmpty                         = errors.New("cart is empty")
@@ -38,6 +46,17 @@ var (
 	ErrFailedToCreatePromotion           = errors.New("failed to create promotion")
 	ErrMustSpecifyProduct                = errors.New("must specify products for product scope promotion")
 	ErrInvalidAmountForDiscountTypePromo = errors.New("invalid amount for discount type promo. cannot be greater than 100%")
+	ErrInvalidAmountForShippingTypePromo = errors.New("invalid amount for shipping type promo. cannot be greater than 100%")
 	ErrInvalidAddress                    = errors.New("invalid address")
 	ErrOrderDetailNotFound               = errors.New(shared.ErrOrderDetailNotFound.Message)
+	ErrPromotionNotFound                 = errors.New(shared.ErrPromotionNotFound.Message)
+	ErrNotGlobalPromotion                = errors.New("promotion is not a global promotion")
+	ErrGlobalPromotionWithProducts       = errors.New("global promotion cannot specify products")
+	ErrFailedToUpdatePromotion           = errors.New("failed to update promotion")
+	ErrFlashSaleLimitExceeded            = errors.New(shared.ErrFlashSaleLimitExceeded.Message)
+	ErrInvalidFlashSalePrice             = errors.New("flash sale price must be lower than variant price")
+	ErrInvalidFlashSaleStock             = errors.New("flash sale stock cannot exceed variant stock")
+	ErrDuplicateFlashSaleVariant         = errors.New("flash sale cannot list the same variant twice")
+	ErrInvalidBundlePromotion            = errors.New("bundle promotion must be product scoped with valid quantities or price")
+	ErrInvalidPromotionPeriod            = errors.New("promotion end date cannot be before start date")
 )
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"digital-test-vm/be/internal/dto"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/utils"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	MediaMaxUploadSize = 5 << 20
+	mediaThumbnailSize = 300
+	mediaMaxPixels     = 40000000
+)
+
+/* what an upload is for decides how large it may be */
+var mediaSizeLimits = map[string]int{
+	"product": MediaMaxUploadSize,
+	"review":  MediaMaxUploadSize,
+	"profile": 2 << 20,
+}
+
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type mediaUsecase struct {
+	repo *repo.Repo
+}
+
+type MediaUsecase interface {
+	UploadImage(ctx context.Context, userID uint64, kind string, body []byte) (*dto.MediaUploadResponse, error)
+}
+
+func NewMediaUsecase(repo *repo.Repo) MediaUsecase {
+	return &mediaUsecase{
+		repo: repo,
+	}
+}
+
+/* the type is sniffed from the content, the name and extension sent by the client are not trusted */
+func (u *mediaUsecase) UploadImage(ctx context.Context, userID uint64, kind string, body []byte) (*dto.MediaUploadResponse, error) {
+	limit, ok := mediaSizeLimits[kind]
+	if !ok {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", ErrInvalidMediaKind)
+	}
+	if len(body) > limit {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", ErrMediaTooLarge)
+	}
+
+	contentType := http.DetectContentType(body)
+	ext, ok := mediaExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %s: %w", contentType, ErrUnsupportedMediaType)
+	}
+
+	/* the dimensions are checked before decoding so a small file can not expand into a huge image */
+	config, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %s: %w", err, ErrUnsupportedMediaType)
+	}
+	if config.Width*config.Height > mediaMaxPixels {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", ErrMediaTooLarge)
+	}
+	img, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %s: %w", err, ErrUnsupportedMediaType)
+	}
+
+	thumbnail := &bytes.Buffer{}
+	thumbnailType, thumbnailExt := "image/png", ".png"
+	if contentType == "image/jpeg" {
+		thumbnailType, thumbnailExt = contentType, ext
+		err = jpeg.Encode(thumbnail, utils.Thumbnail(img, mediaThumbnailSize), &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(thumbnail, utils.Thumbnail(img, mediaThumbnailSize))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", err)
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", err)
+	}
+	key := fmt.Sprintf("%s/%d/%s", kind, userID, hex.EncodeToString(name))
+
+	url, err := u.repo.MediaStorage.Put(ctx, key+ext, contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", err)
+	}
+	thumbnailURL, err := u.repo.MediaStorage.Put(ctx, key+"_thumb"+thumbnailExt, thumbnailType, thumbnail.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("mediaUsecase/UploadImage: %w", err)
+	}
+
+	return &dto.MediaUploadResponse{
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		ContentType:  contentType,
+		Size:         len(body),
+		Width:        config.Width,
+		Height:       config.Height,
+	}, nil
+}
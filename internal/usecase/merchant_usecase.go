@@ -6,6 +6,7 @@ import (
 	"digital-test-vm/be/internal/model"
 	repo "digital-test-vm/be/internal/repository"
 	"fmt"
+	"math"
 )
 
 type MerchantUsecase interface {
@@ -47,8 +48,18 @@ func (u *merchantUsecase) GetMerchantByUsername(ctx context.Context, username st
 		return nil, fmt.Errorf("merchantUsecase/GetMerchantByUsername: %s: %w", ErrFailedGettingMerchantData, err)
 	}
 
+	breakdown, err := u.repo.MerchantRepo.GetMerchantRatingBreakdown(ctx, merchant.ID)
+	if err != nil {
+		return nil, fmt.Errorf("merchantUsecase/GetMerchantByUsername: %s: %w", ErrFailedGettingMerchantData, err)
+	}
+	breakdown.ReviewAverage = math.Round(breakdown.ReviewAverage*100) / 100
+	breakdown.OnTimeRate = math.Round(breakdown.OnTimeRate*100) / 100
+	breakdown.AverageShipHours = math.Round(breakdown.AverageShipHours*10) / 10
+	breakdown.CanceledRate = math.Round(breakdown.CanceledRate*100) / 100
+
 	merchantDTO := dto.MerchantToDTO(*merchant)
 	merchantDTO.Username = &user.Username
 	merchantDTO.Address = dto.AddressToDTO(address)
+	merchantDTO.RatingBreakdown = breakdown
 	return merchantDTO, nil
 }
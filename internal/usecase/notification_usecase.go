@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/utils"
+	"fmt"
+	"html"
+)
+
+type NotificationUsecase interface {
+	GetNotifications(ctx context.Context, userId uint64, req dto.ListNotificationRequest) (*dto.NotificationListResponse, string, error)
+	MarkNotificationRead(ctx context.Context, userId uint64, notificationId uint64) error
+	MarkAllNotificationsRead(ctx context.Context, userId uint64) error
+}
+
+type notificationUsecase struct {
+	repo *repo.Repo
+}
+
+func NewNotificationUsecase(repo *repo.Repo) NotificationUsecase {
+	return &notificationUsecase{repo: repo}
+}
+
+func (u *notificationUsecase) GetNotifications(ctx context.Context, userId uint64, req dto.ListNotificationRequest) (*dto.NotificationListResponse, string, error) {
+	notifications, nextCursor, err := u.repo.NotificationRepo.GetNotifications(ctx, userId, req.Cursor, req.Limit, req.UnreadOnly)
+	if err != nil {
+		return nil, "", fmt.Errorf("notificationUsecase/GetNotifications: %w", err)
+	}
+	unread, err := u.repo.NotificationRepo.CountUnreadNotifications(ctx, userId)
+	if err != nil {
+		return nil, "", fmt.Errorf("notificationUsecase/GetNotifications: %w", err)
+	}
+	return &dto.NotificationListResponse{Notifications: notifications, UnreadCount: unread}, nextCursor, nil
+}
+
+func (u *notificationUsecase) MarkNotificationRead(ctx context.Context, userId uint64, notificationId uint64) error {
+	if err := u.repo.NotificationRepo.MarkNotificationRead(ctx, userId, notificationId); err != nil {
+		return fmt.Errorf("notificationUsecase/MarkNotificationRead: %w", err)
+	}
+	return nil
+}
+
+func (u *notificationUsecase) MarkAllNotificationsRead(ctx context.Context, userId uint64) error {
+	if err := u.repo.NotificationRepo.MarkAllNotificationsRead(ctx, userId); err != nil {
+		return fmt.Errorf("notificationUsecase/MarkAllNotificationsRead: %w", err)
+	}
+	return nil
+}
+
+/* keeps the notification in the app and mails the same message, a failed email does not take the notification back */
+func notify(ctx context.Context, r *repo.Repo, email string, notification model.Notification) error {
+	if err := r.NotificationRepo.CreateNotifications(ctx, []model.Notification{notification}); err != nil {
+		return err
+	}
+	_ = utils.GenerateAndSendEmail(email, fmt.Sprintf("<b>%s</b><p>%s</p>", html.EscapeString(notification.Title), html.EscapeString(notification.Message)))
+	return nil
+}
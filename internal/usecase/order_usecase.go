@@ -5,6 +5,7 @@ import (
 	"digital-test-vm/be/internal/dto"
 	repo "digital-test-vm/be/internal/repository"
 	"digital-test-vm/be/internal/shared"
+	"errors"
 	"fmt"
 )
 
@@ -13,13 +14,14 @@ type orderUsecase struct {
 }
 
 type OrderUsecase interface {
-	GetListTransaction(c context.Context, req dto.ListTransactionRequest, cartId, userId uint64) ([]dto.ListTransactionResponse, error)
+	GetListTransaction(c context.Context, req dto.ListTransactionRequest, cartId, userId uint64) ([]dto.ListTransactionResponse, string, error)
 	GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) (*dto.PaginationInfo, error)
 	ChangeOrderStatusToProcessed(c context.Context, orderDetailId uint64, merchantsId uint64) error
 	ChangeOrderStatusToOnDelivery(c context.Context, orderDetailId uint64, merchantsId uint64) error
 	ChangeOrderStatusToDelivered(c context.Context, orderDetailId uint64) error
 	ChangeOrderStatusToCompleted(c context.Context, orderDetailId uint64, userCart uint64) error
 	ChangeOrderStatusToReviewed(c context.Context, orderDetailId uint64, userCart uint64) error
+	ChangeOrderStatusToCanceled(c context.Context, orderDetailId uint64, merchantsId uint64) error
 	GetOrderDetail(c context.Context, id, cartId uint64) (*dto.ListTransactionResponse, error)
 	GetListSellerOrder(c context.Context, req dto.ListSellerTransactionRequest, merchantId uint64) ([]dto.ListSellerOrderResponse, error)
 	GetPaginationListSellerOrder(c context.Context, req dto.ListSellerTransactionRequest, merchantId uint64) (*dto.PaginationInfo, error)
@@ -32,20 +34,20 @@ func NewOrderUsecase(repo *repo.Repo) OrderUsecase {
 	}
 }
 
-func (u *orderUsecase) GetListTransaction(c context.Context, req dto.ListTransactionRequest, cartId, userId uint64) ([]dto.ListTransactionResponse, error) {
+func (u *orderUsecase) GetListTransaction(c context.Context, req dto.ListTransactionRequest, cartId, userId uint64) ([]dto.ListTransactionResponse, string, error) {
 	var res []dto.ListTransactionResponse
-	orders, err := u.repo.OrderRepo.GetPaginationListTransaction(c, req, cartId)
+	orders, nextCursor, err := u.repo.OrderRepo.GetPaginationListTransaction(c, req, cartId)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if len(orders) == 0 {
-		return nil, fmt.Errorf("orderUsecase/GetListTransaction %w", ErrListTransactionNotFound)
+		return nil, "", fmt.Errorf("orderUsecase/GetListTransaction %w", ErrListTransactionNotFound)
 	}
 
 	for _, v := range orders {
 		listTransaction, err := u.repo.OrderRepo.GetListTransactionByOrderId(c, req, v.OrderId)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		var order dto.ListTransactionResponse
 		order.OrderId = v.OrderId
@@ -55,7 +57,7 @@ func (u *orderUsecase) GetListTransaction(c context.Context, req dto.ListTransac
 		for _, v := range listTransaction {
 			merchants, err := u.repo.MerchantRepo.FindMerchantByID(c, v.MerchantId)
 			if err != nil {
-				return nil, fmt.Errorf("orderUsecase/GetListTransaction %w", ErrInternalServerError)
+				return nil, "", fmt.Errorf("orderUsecase/GetListTransaction %w", ErrInternalServerError)
 			}
 			orderDetails := dto.ListTransactionOrder{
 				OrderDetailId: v.OrderDetailId,
@@ -71,7 +73,7 @@ func (u *orderUsecase) GetListTransaction(c context.Context, req dto.ListTransac
 			}
 			product, err := u.repo.OrderRepo.GetOrderDetailsProduct(c, v.OrderDetailId, userId)
 			if err != nil {
-				return nil, fmt.Errorf("orderUsecase/GetListTransaction %w", ErrInternalServerError)
+				return nil, "", fmt.Errorf("orderUsecase/GetListTransaction %w", ErrInternalServerError)
 			}
 			orderDetails.ListTransactionProduct = product
 			listTransactionOrder = append(listTransactionOrder, orderDetails)
@@ -79,15 +81,14 @@ func (u *orderUsecase) GetListTransaction(c context.Context, req dto.ListTransac
 		order.ListTransactionOrder = listTransactionOrder
 		res = append(res, order)
 	}
-	return res, nil
+	return res, nextCursor, nil
 }
 
 func (u *orderUsecase) GetPaginationListTransaction(c context.Context, req dto.ListTransactionRequest, cartId uint64) (*dto.PaginationInfo, error) {
-	res, err := u.repo.OrderRepo.GetPaginationInfoTransaction(c, req, cartId)
+	length, err := u.repo.OrderRepo.GetPaginationInfoTransaction(c, req, cartId)
 	if err != nil {
 		return nil, err
 	}
-	length := len(res)
 	return &dto.PaginationInfo{TotalItems: int64(length), TotalPages: (int64(length) + int64(10) - 1) / int64(10), CurrentPage: int64(req.Page)}, nil
 }
 
@@ -210,6 +211,45 @@ func (u *orderUsecase) ChangeOrderStatusToReviewed(c context.Context, orderDetai
 	return nil
 }
 
+func (u *orderUsecase) ChangeOrderStatusToCanceled(c context.Context, orderDetailId uint64, merchantsId uint64) error {
+	orderDetail, cartId, err := u.repo.OrderRepo.GetOrderDetailById(c, orderDetailId)
+	if err != nil {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", err)
+	}
+
+	if orderDetail.OrderStatus != shared.WaitingForSeller.String() {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", ErrUnauthorizedAccess)
+	}
+
+	if orderDetail.MerchantId != merchantsId {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", ErrUnauthorizedAccess)
+	}
+
+	cart, err := u.repo.CartRepo.GetCartById(c, cartId)
+	if err != nil {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", err)
+	}
+
+	buyerWallet, err := u.repo.WalletRepo.FindByUserId(c, cart.UserId)
+	if err != nil {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", err)
+	}
+
+	adminWallet, err := u.repo.WalletRepo.FindByUserId(c, shared.ADMIN_WALLET)
+	if err != nil {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", err)
+	}
+
+	err = u.repo.OrderRepo.CancelOrderDetail(c, *orderDetail, &adminWallet.WalletId, &buyerWallet.WalletId)
+	if errors.Is(err, repo.ErrUnauthorizedAccess) {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", ErrUnauthorizedAccess)
+	}
+	if err != nil {
+		return fmt.Errorf("orderUsecase/ChangeOrderStatusToCanceled %w", err)
+	}
+	return nil
+}
+
 func (u *orderUsecase) GetOrderDetail(c context.Context, id, cartId uint64) (*dto.ListTransactionResponse, error) {
 	order, err := u.repo.OrderRepo.GetOrderById(c, id)
 	if err != nil {
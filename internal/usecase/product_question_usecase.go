@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/shared"
+	"fmt"
+	"strings"
+)
+
+type ProductQuestionUsecase interface {
+	CreateQuestion(ctx context.Context, userId uint64, productId uint64, req dto.CreateProductQuestionRequest) (*model.ProductQuestion, error)
+	GetProductQuestions(ctx context.Context, req dto.ListProductQuestionRequest) ([]model.ProductQuestion, string, error)
+	AnswerQuestion(ctx context.Context, userInfo *dto.UserInfo, questionId uint64, req dto.AnswerProductQuestionRequest) error
+	GetUnansweredQuestions(ctx context.Context, userInfo *dto.UserInfo, cursor string, limit int) ([]model.ProductQuestion, string, error)
+}
+
+type productQuestionUsecase struct {
+	repo *repo.Repo
+}
+
+func NewProductQuestionUsecase(repo *repo.Repo) ProductQuestionUsecase {
+	return &productQuestionUsecase{repo: repo}
+}
+
+/* questions can only be asked on products buyers can see */
+func (u *productQuestionUsecase) CreateQuestion(ctx context.Context, userId uint64, productId uint64, req dto.CreateProductQuestionRequest) (*model.ProductQuestion, error) {
+	text := strings.TrimSpace(req.Question)
+	if text == "" {
+		return nil, fmt.Errorf("productQuestionUsecase/CreateQuestion: %w", ErrBlankText)
+	}
+	product, err := u.repo.ProductRepo.IsProductExist(ctx, productId)
+	if err != nil || product.IsDraft || product.DeletedAt != nil || !product.IsActive {
+		return nil, fmt.Errorf("productQuestionUsecase/CreateQuestion: %w", ErrProductNotFound)
+	}
+
+	question := model.ProductQuestion{ProductId: productId, UserId: userId, Question: text}
+	if err := u.repo.ProductQuestionRepo.CreateQuestion(ctx, &question); err != nil {
+		return nil, fmt.Errorf("productQuestionUsecase/CreateQuestion: %w", err)
+	}
+	return &question, nil
+}
+
+func (u *productQuestionUsecase) GetProductQuestions(ctx context.Context, req dto.ListProductQuestionRequest) ([]model.ProductQuestion, string, error) {
+	questions, nextCursor, err := u.repo.ProductQuestionRepo.GetProductQuestions(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("productQuestionUsecase/GetProductQuestions: %w", err)
+	}
+	return questions, nextCursor, nil
+}
+
+/* only the merchant that owns the product answers, the buyer that asked is told about it */
+func (u *productQuestionUsecase) AnswerQuestion(ctx context.Context, userInfo *dto.UserInfo, questionId uint64, req dto.AnswerProductQuestionRequest) error {
+	if userInfo.MerchantId == nil {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	answer := strings.TrimSpace(req.Answer)
+	if answer == "" {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", ErrBlankText)
+	}
+	question, err := u.repo.ProductQuestionRepo.GetQuestion(ctx, questionId)
+	if err != nil {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", err)
+	}
+	product, err := u.repo.ProductRepo.IsProductExist(ctx, question.ProductId)
+	if err != nil || product.DeletedAt != nil {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", ErrProductNotFound)
+	}
+	if product.MerchantId != *userInfo.MerchantId {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", ErrUnauthorizedProduct)
+	}
+	if err := u.repo.ProductQuestionRepo.AnswerQuestion(ctx, questionId, answer); err != nil {
+		return fmt.Errorf("productQuestionUsecase/AnswerQuestion: %w", err)
+	}
+
+	if asker, err := u.repo.UserRepo.FindById(ctx, question.UserId); err == nil {
+		_ = notify(ctx, u.repo, asker.Email, model.Notification{
+			UserId:    asker.ID,
+			Type:      shared.NotificationQuestionAnswered.String(),
+			Title:     "Your question was answered: " + product.Title,
+			Message:   answer,
+			ProductId: &product.ID,
+		})
+	}
+	return nil
+}
+
+func (u *productQuestionUsecase) GetUnansweredQuestions(ctx context.Context, userInfo *dto.UserInfo, cursor string, limit int) ([]model.ProductQuestion, string, error) {
+	if userInfo.MerchantId == nil {
+		return nil, "", fmt.Errorf("productQuestionUsecase/GetUnansweredQuestions: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	questions, nextCursor, err := u.repo.ProductQuestionRepo.GetUnansweredQuestions(ctx, *userInfo.MerchantId, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("productQuestionUsecase/GetUnansweredQuestions: %w", err)
+	}
+	return questions, nextCursor, nil
+}
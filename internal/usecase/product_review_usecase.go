@@ -8,19 +8,39 @@ import (
 	"digital-test-vm/be/internal/shared"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
-var ErrRatingNotValid = errors.New(shared.ErrRatingNotValid.Message)
+var (
+	ErrRatingNotValid  = errors.New(shared.ErrRatingNotValid.Message)
+	ErrVoteOwnReview   = errors.New(shared.ErrVoteOwnReview.Message)
+	ErrReviewExpired   = errors.New(shared.ErrReviewExpired.Message)
+	ErrNotReviewAuthor = errors.New("review belongs to another user")
+)
+
+const (
+	/* how long after posting a reply the merchant may still correct it */
+	reviewReplyEditWindow = 24 * time.Hour
+	/* how long after posting a review its author may still change it, deleting is always possible */
+	reviewEditWindow = 30 * 24 * time.Hour
+)
 
 type productReviewUsecase struct {
 	repo *repo.Repo
 }
 
 type ProductReviewUsecase interface {
-	GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]dto.ProductReview, error)
+	GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]dto.ProductReview, string, error)
 	GetPaginationProductReview(c context.Context, req dto.GetProductReviewRequest) (dto.PaginationInfo, error)
 	CreateProductReview(c context.Context, req dto.CreateProductReviewRequest, userId uint64) error
 	IsProductReviewed(c context.Context, req dto.IsReviewRequest, userId uint64) (bool, error)
+	ReplyReview(c context.Context, userInfo *dto.UserInfo, reviewId uint64, req dto.ReviewReplyRequest) error
+	EditReviewReply(c context.Context, userInfo *dto.UserInfo, reviewId uint64, req dto.ReviewReplyRequest) error
+	GetMerchantReviews(c context.Context, userInfo *dto.UserInfo, cursor string, limit int, unrepliedOnly bool) ([]model.MerchantReview, string, error)
+	VoteHelpful(c context.Context, reviewId, userId uint64, helpful bool) error
+	UpdateProductReview(c context.Context, reviewId, userId uint64, req dto.UpdateProductReviewRequest) error
+	DeleteProductReview(c context.Context, reviewId, userId uint64) error
 }
 
 func NewProductReviewUsecase(repo *repo.Repo) ProductReviewUsecase {
@@ -29,37 +49,45 @@ func NewProductReviewUsecase(repo *repo.Repo) ProductReviewUsecase {
 	}
 }
 
-func (u *productReviewUsecase) GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]dto.ProductReview, error) {
+func (u *productReviewUsecase) GetProductReview(c context.Context, req dto.GetProductReviewRequest) ([]dto.ProductReview, string, error) {
 	res := []dto.ProductReview{}
-	review, err := u.repo.ProductReviewRepo.GetProductReview(c, req)
+	review, nextCursor, err := u.repo.ProductReviewRepo.GetProductReview(c, req)
 	if err != nil {
-		return res, err
+		return res, "", err
+	}
+	voted := map[uint64]bool{}
+	if req.ViewerId != nil {
+		ids := make([]uint64, len(review))
+		for i, v := range review {
+			ids[i] = v.ID
+		}
+		if voted, err = u.repo.ProductReviewRepo.GetHelpfulVotes(c, *req.ViewerId, ids); err != nil {
+			return res, "", err
+		}
 	}
 	for _, v := range review {
 		image := []string{}
 		userName, profilePicture, err := u.repo.UserRepo.FindNameAndPictureById(c, v.UserId)
 		if err != nil {
-			return res, err
+			return res, "", err
 		}
 		urls, err := u.repo.ProductPhotoRepo.GetProductReviewPhotoByProductReviewId(c, v.ID)
 		if err != nil {
-			return res, err
+			return res, "", err
 		}
 		for _, url := range urls {
 			image = append(image, url.Url)
 		}
-		res = append(res, dto.ProductReview{ID: v.ID, Rating: v.Rating, Images: image, Description: v.Description, UserName: userName, ProfilePicture: profilePicture, CreatedAt: v.CreatedAt, UpdatedAt: v.UpdatedAt})
+		res = append(res, dto.ProductReview{ID: v.ID, Rating: v.Rating, Images: image, Description: v.Description, Reply: dto.ToReviewReply(v), HelpfulCount: v.HelpfulCount, IsHelpful: voted[v.ID], UserName: userName, ProfilePicture: profilePicture, CreatedAt: v.CreatedAt, UpdatedAt: v.UpdatedAt})
 	}
-	return res, nil
+	return res, nextCursor, nil
 }
 
 func (u *productReviewUsecase) GetPaginationProductReview(c context.Context, req dto.GetProductReviewRequest) (dto.PaginationInfo, error) {
-	review, err := u.repo.ProductReviewRepo.GetAllProductReview(c, req)
+	length, err := u.repo.ProductReviewRepo.CountProductReview(c, req)
 	if err != nil {
 		return dto.PaginationInfo{}, err
 	}
-
-	length := len(review)
 	return dto.PaginationInfo{TotalItems: int64(length), TotalPages: (int64(length) + int64(req.Limit) - 1) / int64(req.Limit)}, nil
 }
 
@@ -78,11 +106,10 @@ func (u *productReviewUsecase) CreateProductReview(c context.Context, req dto.Cr
 		return fmt.Errorf("productReviewUsecase/CreateProductReview %w", ErrProductReviewed)
 	}
 	productReview := model.ProductReview{ProductId: req.ProductId, UserId: userId, Rating: req.Rating, Photos: req.Images, Description: req.Comments, OrderDetailId: req.OrderDetailId}
-	product, err := u.repo.ProductRepo.IsProductExist(c, req.ProductId)
-	if err != nil {
+	if _, err := u.repo.ProductRepo.IsProductExist(c, req.ProductId); err != nil {
 		return fmt.Errorf("productReviewUsecase/shared.ErrProductNotFound %w", ErrProductNotFound)
 	}
-	if err = u.repo.ProductReviewRepo.CreateProductReview(c, productReview, product); err != nil {
+	if err = u.repo.ProductReviewRepo.CreateProductReview(c, productReview); err != nil {
 		return err
 	}
 	return nil
@@ -100,3 +127,138 @@ func (u *productReviewUsecase) IsProductReviewed(c context.Context, req dto.IsRe
 	}
 	return true, nil
 }
+
+/* the reviewer is told about the first reply, a correction is not announced again */
+func (u *productReviewUsecase) ReplyReview(c context.Context, userInfo *dto.UserInfo, reviewId uint64, req dto.ReviewReplyRequest) error {
+	reply := strings.TrimSpace(req.Reply)
+	if reply == "" {
+		return fmt.Errorf("productReviewUsecase/ReplyReview %w", ErrBlankText)
+	}
+	review, product, err := u.merchantReview(c, userInfo, reviewId)
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/ReplyReview %w", err)
+	}
+	if err := u.repo.ProductReviewRepo.ReplyReview(c, review.ID, reply); err != nil {
+		return fmt.Errorf("productReviewUsecase/ReplyReview %w", err)
+	}
+
+	if reviewer, err := u.repo.UserRepo.FindById(c, review.UserId); err == nil {
+		_ = notify(c, u.repo, reviewer.Email, model.Notification{
+			UserId:    reviewer.ID,
+			Type:      shared.NotificationReviewReplied.String(),
+			Title:     "The seller replied to your review of " + product.Title,
+			Message:   reply,
+			ProductId: &product.ID,
+		})
+	}
+	return nil
+}
+
+func (u *productReviewUsecase) EditReviewReply(c context.Context, userInfo *dto.UserInfo, reviewId uint64, req dto.ReviewReplyRequest) error {
+	reply := strings.TrimSpace(req.Reply)
+	if reply == "" {
+		return fmt.Errorf("productReviewUsecase/EditReviewReply %w", ErrBlankText)
+	}
+	review, _, err := u.merchantReview(c, userInfo, reviewId)
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/EditReviewReply %w", err)
+	}
+	if err := u.repo.ProductReviewRepo.EditReviewReply(c, review.ID, reply, reviewReplyEditWindow); err != nil {
+		return fmt.Errorf("productReviewUsecase/EditReviewReply %w", err)
+	}
+	return nil
+}
+
+func (u *productReviewUsecase) GetMerchantReviews(c context.Context, userInfo *dto.UserInfo, cursor string, limit int, unrepliedOnly bool) ([]model.MerchantReview, string, error) {
+	if userInfo.MerchantId == nil {
+		return nil, "", fmt.Errorf("productReviewUsecase/GetMerchantReviews %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	reviews, nextCursor, err := u.repo.ProductReviewRepo.GetMerchantReviews(c, *userInfo.MerchantId, cursor, limit, unrepliedOnly)
+	if err != nil {
+		return nil, "", fmt.Errorf("productReviewUsecase/GetMerchantReviews %w", err)
+	}
+	return reviews, nextCursor, nil
+}
+
+/* a review can only be answered by the merchant selling the reviewed product */
+func (u *productReviewUsecase) merchantReview(c context.Context, userInfo *dto.UserInfo, reviewId uint64) (*model.ProductReview, *model.Product, error) {
+	if userInfo.MerchantId == nil {
+		return nil, nil, ErrWrongUserTryingToAccessMerchant
+	}
+	review, err := u.repo.ProductReviewRepo.GetReview(c, reviewId)
+	if err != nil {
+		return nil, nil, err
+	}
+	product, err := u.repo.ProductRepo.IsProductExist(c, review.ProductId)
+	if err != nil {
+		return nil, nil, ErrProductNotFound
+	}
+	if product.MerchantId != *userInfo.MerchantId {
+		return nil, nil, ErrUnauthorizedProduct
+	}
+	return &review, &product, nil
+}
+
+/* helpful false takes the vote of the user back */
+func (u *productReviewUsecase) VoteHelpful(c context.Context, reviewId, userId uint64, helpful bool) error {
+	review, err := u.repo.ProductReviewRepo.GetReview(c, reviewId)
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/VoteHelpful %w", err)
+	}
+	if review.UserId == userId {
+		return fmt.Errorf("productReviewUsecase/VoteHelpful %w", ErrVoteOwnReview)
+	}
+	if helpful {
+		err = u.repo.ProductReviewRepo.VoteHelpful(c, reviewId, userId)
+	} else {
+		err = u.repo.ProductReviewRepo.UnvoteHelpful(c, reviewId, userId)
+	}
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/VoteHelpful %w", err)
+	}
+	return nil
+}
+
+func (u *productReviewUsecase) UpdateProductReview(c context.Context, reviewId, userId uint64, req dto.UpdateProductReviewRequest) error {
+	if req.Rating < 1 || req.Rating > 5 {
+		return fmt.Errorf("productReviewUsecase/UpdateProductReview %w", ErrRatingNotValid)
+	}
+	review, err := u.authorReview(c, reviewId, userId)
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/UpdateProductReview %w", err)
+	}
+	if time.Since(review.CreatedAt) > reviewEditWindow {
+		return fmt.Errorf("productReviewUsecase/UpdateProductReview %w", ErrReviewExpired)
+	}
+
+	review.Rating = req.Rating
+	review.Description = strings.TrimSpace(req.Comments)
+	review.Photos = strings.Join(req.Images, ",")
+	if err := u.repo.ProductReviewRepo.UpdateProductReview(c, *review, req.Images); err != nil {
+		return fmt.Errorf("productReviewUsecase/UpdateProductReview %w", err)
+	}
+	return nil
+}
+
+func (u *productReviewUsecase) DeleteProductReview(c context.Context, reviewId, userId uint64) error {
+	review, err := u.authorReview(c, reviewId, userId)
+	if err != nil {
+		return fmt.Errorf("productReviewUsecase/DeleteProductReview %w", err)
+	}
+	if err := u.repo.ProductReviewRepo.DeleteProductReview(c, *review); err != nil {
+		return fmt.Errorf("productReviewUsecase/DeleteProductReview %w", err)
+	}
+	return nil
+}
+
+/* a review can only be changed by the buyer that wrote it */
+func (u *productReviewUsecase) authorReview(c context.Context, reviewId, userId uint64) (*model.ProductReview, error) {
+	review, err := u.repo.ProductReviewRepo.GetReview(c, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserId != userId {
+		return nil, ErrNotReviewAuthor
+	}
+	return &review, nil
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -20,14 +22,26 @@ type productUsecase struct {
 
 type ProductUsecase interface {
 	ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]dto.ListProduct, *dto.PaginationInfo, error)
-	ListProducts(ctx context.Context, args dto.ListProductQueries) ([]dto.ListProduct, *dto.PaginationInfo, error)
-	GetDetailProduct(c context.Context, id uint64) (*dto.ProductDetail, error)
+	ListProducts(ctx context.Context, args dto.ListProductQueries) ([]dto.ListProduct, *dto.PaginationInfo, *dto.ProductFacetsResponse, error)
+	GetDetailProduct(c context.Context, id uint64, viewerID *uint64, viewerIP string) (*dto.ProductDetail, error)
 	ListProductsByMerchantUsername(ctx context.Context, username string, args dto.ListProductByMerchantQueries) ([]dto.ListProduct, *dto.PaginationInfo, error)
 	DeactivateProduct(c context.Context, productId uint64, userId uint64) error
 	ActivateProduct(c context.Context, productId uint64, userId uint64) error
 	CreateProduct(ctx context.Context, userID uint64, createProductDTO *dto.ManageProduct) error
 	GetProductForEdit(ctx context.Context, userInfo *dto.UserInfo, productID uint64) (*dto.ManageProduct, error)
 	UpdateProduct(ctx context.Context, userInfo *dto.UserInfo, updateProductDTO *dto.ManageProduct) error
+	ImportProducts(ctx context.Context, userInfo *dto.UserInfo, batch *dto.ProductImportBatch) *dto.ProductImportReport
+	ExportProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.ManageProduct, error)
+	UpdateVariants(ctx context.Context, userInfo *dto.UserInfo, variants []dto.UpdateVariant) error
+	GetStockHistory(ctx context.Context, userInfo *dto.UserInfo, req dto.StockMovementRequest) ([]model.StockMovement, string, error)
+	GetDraftProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.DraftProductResponse, error)
+	DeleteProduct(ctx context.Context, userInfo *dto.UserInfo, productID uint64) error
+	RestoreProduct(ctx context.Context, userInfo *dto.UserInfo, productID uint64) error
+	GetArchivedProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.ArchivedProductResponse, error)
+	SuggestProducts(ctx context.Context, prefix string, limit int64) (*dto.ProductSuggestionResponse, error)
+	ListForYouProducts(ctx context.Context, viewerID *uint64, cursor string, page uint64, limit uint64) ([]dto.ListProduct, *dto.PaginationInfo, error)
+	ListRelatedProducts(ctx context.Context, productID uint64, limit uint64) ([]dto.ListProduct, error)
+	ListRecentlyViewedProducts(ctx context.Context, userID uint64, limit int64) ([]dto.ListProduct, error)
 }
 
 func NewProductUsecase(repo *repo.Repo) ProductUsecase {
@@ -37,7 +51,7 @@ func NewProductUsecase(repo *repo.Repo) ProductUsecase {
 }
 
 func (u *productUsecase) ListProductsByMerchantID(ctx context.Context, merchantID uint64, args dto.ListProductByMerchantQueries) ([]dto.ListProduct, *dto.PaginationInfo, error) {
-	products, totalItems, err := u.repo.ProductRepo.ListProductsByMerchantID(ctx, merchantID, args)
+	products, totalItems, nextCursor, err := u.repo.ProductRepo.ListProductsByMerchantID(ctx, merchantID, args)
 	if err != nil {
 		return nil, nil, fmt.Errorf("productUsecase/ListProductsByMerchantID: %s: %w", ErrFailedGettingProductsData, err)
 	}
@@ -46,6 +60,7 @@ func (u *productUsecase) ListProductsByMerchantID(ctx context.Context, merchantI
 		TotalItems:  int64(totalItems),
 		TotalPages:  (int64(totalItems) + int64(args.Limit) - 1) / int64(args.Limit),
 		CurrentPage: int64(args.Page),
+		NextCursor:  nextCursor,
 	}
 
 	return fillListProductDTOs(products), &pageInfo, nil
@@ -57,7 +72,7 @@ func (u *productUsecase) ListProductsByMerchantUsername(ctx context.Context, use
 		return nil, nil, fmt.Errorf("productUsecase/ListProductsByMerchantUsername: %s: %w", ErrFailedGettingProductsData, err)
 	}
 
-	products, totalItems, err := u.repo.ProductRepo.ListProductsByUserID(ctx, user.ID, args)
+	products, totalItems, nextCursor, err := u.repo.ProductRepo.ListProductsByUserID(ctx, user.ID, args)
 	if err != nil {
 		return nil, nil, fmt.Errorf("productUsecase/ListProductsByMerchantUsername: %s: %w", ErrFailedGettingProductsData, err)
 	}
@@ -66,26 +81,74 @@ func (u *productUsecase) ListProductsByMerchantUsername(ctx context.Context, use
 		TotalItems:  int64(totalItems),
 		TotalPages:  (int64(totalItems) + int64(args.Limit) - 1) / int64(args.Limit),
 		CurrentPage: int64(args.Page),
+		NextCursor:  nextCursor,
 	}
 
 	return fillListProductDTOs(products), &pageInfo, nil
 }
 
-func (u *productUsecase) ListProducts(ctx context.Context, args dto.ListProductQueries) ([]dto.ListProduct, *dto.PaginationInfo, error) {
-	products, totalItems, err := u.repo.ProductRepo.ListProducts(ctx, args)
+func (u *productUsecase) ListProducts(ctx context.Context, args dto.ListProductQueries) ([]dto.ListProduct, *dto.PaginationInfo, *dto.ProductFacetsResponse, error) {
+	products, totalItems, nextCursor, err := u.repo.ProductRepo.ListProducts(ctx, args)
 	if err != nil {
-		return nil, nil, fmt.Errorf("productUsecase/ListProducts: %s: %w", ErrFailedGettingProductsData, err)
+		return nil, nil, nil, fmt.Errorf("productUsecase/ListProducts: %s: %w", ErrFailedGettingProductsData, err)
 	}
 
 	pageInfo := dto.PaginationInfo{
 		TotalItems:  int64(totalItems),
 		TotalPages:  (int64(totalItems) + int64(args.Limit) - 1) / int64(args.Limit),
 		CurrentPage: int64(args.Page),
+		NextCursor:  nextCursor,
+	}
+
+	var facets *dto.ProductFacetsResponse
+	if args.WithFacets {
+		productFacets, err := u.repo.ProductRepo.ListProductFacets(ctx, args)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("productUsecase/ListProducts: %s: %w", ErrFailedGettingProductsData, err)
+		}
+		facets = dto.ToProductFacetsResponse(productFacets)
+	}
+
+	return fillListProductDTOs(products), &pageInfo, facets, nil
+}
+
+func (u *productUsecase) ListForYouProducts(ctx context.Context, viewerID *uint64, cursor string, page uint64, limit uint64) ([]dto.ListProduct, *dto.PaginationInfo, error) {
+	products, totalItems, nextCursor, err := u.repo.RecommendationRepo.ListRecommendedProducts(ctx, viewerID, cursor, page, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("productUsecase/ListForYouProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+
+	pageInfo := dto.PaginationInfo{
+		TotalItems:  int64(totalItems),
+		TotalPages:  (int64(totalItems) + int64(limit) - 1) / int64(limit),
+		CurrentPage: int64(page),
+		NextCursor:  nextCursor,
 	}
 
 	return fillListProductDTOs(products), &pageInfo, nil
 }
 
+func (u *productUsecase) ListRelatedProducts(ctx context.Context, productID uint64, limit uint64) ([]dto.ListProduct, error) {
+	if _, err := u.repo.ProductRepo.IsProductExist(ctx, productID); err != nil {
+		return nil, fmt.Errorf("productUsecase/ListRelatedProducts: %w", err)
+	}
+
+	products, err := u.repo.RecommendationRepo.ListRelatedProducts(ctx, productID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/ListRelatedProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+
+	return fillListProductDTOs(products), nil
+}
+
+func (u *productUsecase) ListRecentlyViewedProducts(ctx context.Context, userID uint64, limit int64) ([]dto.ListProduct, error) {
+	products, err := u.repo.ProductViewRepo.ListRecentlyViewedProducts(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/ListRecentlyViewedProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+	return fillListProductDTOs(products), nil
+}
+
 func fillListProductDTOs(products []model.ListProduct) []dto.ListProduct {
 	productDTOs := make([]dto.ListProduct, len(products))
 	for i, p := range products {
@@ -94,7 +157,7 @@ func fillListProductDTOs(products []model.ListProduct) []dto.ListProduct {
 	return productDTOs
 }
 
-func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (*dto.ProductDetail, error) {
+func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64, viewerID *uint64, viewerIP string) (*dto.ProductDetail, error) {
 	product, err := u.repo.ProductRepo.GetDetailProduct(c, productId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -119,6 +182,8 @@ func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (
 	}
 	maxPrice := variant[0].Price
 	minPrice := variant[0].Price
+	var minSalePrice *decimal.Decimal
+	var flashSaleEndDate *time.Time
 	variantMap := make(map[string]*model.VariantParent)
 	for i := 0; i < len(variant); i++ {
 		typeNameParent, groupNameParent := variant[i].TypeNameParent, variant[i].GroupNameParent
@@ -129,18 +194,22 @@ func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (
 		if variant[i].Price.LessThan(minPrice) {
 			minPrice = variant[i].Price
 		}
+		if variant[i].SalePrice != nil && (minSalePrice == nil || variant[i].SalePrice.LessThan(*minSalePrice)) {
+			minSalePrice = variant[i].SalePrice
+			flashSaleEndDate = variant[i].SaleEndDate
+		}
 
 		if variantMap[typeNameParent] == nil {
 			if typeNameChild == "" {
-				variantMap[variant[i].TypeNameParent] = &model.VariantParent{VariantCombinationProductId: variant[i].VariantCombinationProductId, ParentName: typeNameParent, ParentGroup: groupNameParent, Stock: variant[i].Stock, Price: variant[i].Price, ParentPicture: variant[i].VariantImage}
+				variantMap[variant[i].TypeNameParent] = &model.VariantParent{VariantCombinationProductId: variant[i].VariantCombinationProductId, ParentName: typeNameParent, ParentGroup: groupNameParent, Stock: variant[i].Stock, Price: variant[i].Price, SalePrice: variant[i].SalePrice, SaleStock: variant[i].SaleStock, ParentPicture: variant[i].VariantImage}
 				continue
 			}
-			variantMap[variant[i].TypeNameParent] = &model.VariantParent{ParentName: typeNameParent, ParentGroup: groupNameParent, ParentPicture: variant[i].VariantImage, VariantChild: []model.VariantChild{{ChildName: typeNameChild, ChildGroup: groupNameChild, Price: variant[i].Price, Stock: variant[i].Stock, VariantCombinationProductId: variant[i].VariantCombinationProductId}}}
+			variantMap[variant[i].TypeNameParent] = &model.VariantParent{ParentName: typeNameParent, ParentGroup: groupNameParent, ParentPicture: variant[i].VariantImage, VariantChild: []model.VariantChild{{ChildName: typeNameChild, ChildGroup: groupNameChild, Price: variant[i].Price, Stock: variant[i].Stock, SalePrice: variant[i].SalePrice, SaleStock: variant[i].SaleStock, VariantCombinationProductId: variant[i].VariantCombinationProductId}}}
 		} else {
 			if typeNameChild == "" {
 				continue
 			}
-			child := append(variantMap[typeNameParent].VariantChild, model.VariantChild{ChildName: typeNameChild, ChildGroup: groupNameChild, Price: variant[i].Price, Stock: variant[i].Stock, VariantCombinationProductId: variant[i].VariantCombinationProductId})
+			child := append(variantMap[typeNameParent].VariantChild, model.VariantChild{ChildName: typeNameChild, ChildGroup: groupNameChild, Price: variant[i].Price, Stock: variant[i].Stock, SalePrice: variant[i].SalePrice, SaleStock: variant[i].SaleStock, VariantCombinationProductId: variant[i].VariantCombinationProductId})
 			variantMap[typeNameParent].VariantChild = child
 		}
 	}
@@ -173,6 +242,16 @@ func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (
 		}
 	}
 
+	questions, err := u.repo.ProductQuestionRepo.CountProductQuestions(c, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	/* a failed view record should not hide the product, merchants looking at their own product are not a view */
+	if viewerID == nil || *viewerID != merchant.UserID {
+		_ = u.repo.ProductViewRepo.RecordView(c, viewerID, viewerIP, productId)
+	}
+
 	return &dto.ProductDetail{
 		Id:            product.ID,
 		ProductName:   product.Title,
@@ -183,6 +262,8 @@ func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (
 		Photos:        listImage,
 		MinPrice:      minPrice,
 		MaxPrice:      maxPrice,
+		MinSalePrice:  minSalePrice,
+		SaleEndDate:   flashSaleEndDate,
 		Username:      username,
 		IsHazardous:   product.IsHazardous,
 		IsUsed:        product.IsUsed,
@@ -194,6 +275,8 @@ func (u *productUsecase) GetDetailProduct(c context.Context, productId uint64) (
 		Merchant:      product.Merchant,
 		Variant:       resultVariant,
 		TotalRating:   product.TotalRating,
+		TotalQuestion: questions.Total,
+		TotalAnswered: questions.Answered,
 		TotalSold:     product.TotalSold,
 		CreatedAt:     product.CreatedAt,
 		UpdatedAt:     product.UpdatedAt,
@@ -215,6 +298,9 @@ func (u *productUsecase) DeactivateProduct(c context.Context, productId uint64,
 	if err := u.repo.ProductRepo.DeactivateProduct(c, productId); err != nil {
 		return err
 	}
+	/* the merchant decides from now on, a restock must not show the product again */
+	_ = u.repo.StockAlertRepo.SetAutoDeactivated(c, productId, false)
+	_ = u.repo.SuggestionRepo.IndexProduct(c, productId)
 	return nil
 }
 
@@ -230,13 +316,24 @@ func (u *productUsecase) ActivateProduct(c context.Context, productId uint64, us
 	if product.MerchantId != merchant.ID {
 		return fmt.Errorf("productUsecase/DeactivateProduct %w", ErrUnauthorizedProduct)
 	}
+	if product.DeletedAt != nil {
+		return fmt.Errorf("productUsecase/ActivateProduct %w", ErrProductNotFound)
+	}
+	if product.IsDraft {
+		return fmt.Errorf("productUsecase/ActivateProduct %w", ErrProductIsDraft)
+	}
 	if err := u.repo.ProductRepo.ActivateProduct(c, productId); err != nil {
 		return err
 	}
+	_ = u.repo.StockAlertRepo.SetAutoDeactivated(c, productId, false)
+	_ = u.repo.SuggestionRepo.IndexProduct(c, productId)
 	return nil
 }
 
 func (u *productUsecase) CreateProduct(ctx context.Context, userID uint64, createProductDTO *dto.ManageProduct) error {
+	if !createProductDTO.IsDraft && len(createProductDTO.Variants.Combinations) == 0 {
+		return fmt.Errorf("productUsecase/CreateProduct: %s: %w", ErrFailedCreateProduct, ErrProductIncomplete)
+	}
 	merchant, err := u.repo.MerchantRepo.FindMerchantByUserID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("productUsecase/CreateProduct: %s: %w", ErrFailedCreateProduct, err)
@@ -245,6 +342,8 @@ func (u *productUsecase) CreateProduct(ctx context.Context, userID uint64, creat
 	if err != nil {
 		return fmt.Errorf("productUsecase/CreateProduct: %s: %w", ErrFailedCreateProduct, err)
 	}
+	/* the product is already saved, a failed suggestion refresh is picked up by the nightly rebuild */
+	_ = u.repo.SuggestionRepo.IndexProduct(ctx, createProductDTO.ID)
 	return nil
 }
 
@@ -313,6 +412,10 @@ func (u *productUsecase) GetProductForEdit(ctx context.Context, userInfo *dto.Us
 		}
 		variantCombinations = append(variantCombinations, *mvc)
 	}
+	/* drafts may not have variants yet */
+	if parentGroup == nil {
+		parentGroup = &dto.ManageVariantGroup{}
+	}
 	parentGroup.Types = parentTypes
 	if childGroup != nil {
 		childGroup.Types = childTypes
@@ -335,15 +438,265 @@ func (u *productUsecase) UpdateProduct(ctx context.Context, userInfo *dto.UserIn
 	if p.MerchantId != *userInfo.MerchantId {
 		return fmt.Errorf("productUsecase/UpdateProduct: %s: %w", ErrWrongUserTryingToAccessMerchant, err)
 	}
+	if p.DeletedAt != nil {
+		return fmt.Errorf("productUsecase/UpdateProduct: %s: %w", ErrFailedUpdateProduct, ErrProductNotFound)
+	}
+	if !updateProductDTO.IsDraft && len(updateProductDTO.Variants.Combinations) == 0 {
+		return fmt.Errorf("productUsecase/UpdateProduct: %s: %w", ErrFailedUpdateProduct, ErrProductIncomplete)
+	}
 
 	err = u.repo.ProductRepo.UpdateProduct(ctx, p.MerchantId, updateProductDTO)
 	if err != nil {
 		return fmt.Errorf("productUsecase/UpdateProduct: %s: %w", ErrFailedUpdateProduct, err)
 	}
+	_ = u.repo.SuggestionRepo.IndexProduct(ctx, p.ID)
 
 	return nil
 }
 
+/* every product is saved in its own transaction so one bad product does not hold back the rest of the file */
+func (u *productUsecase) ImportProducts(ctx context.Context, userInfo *dto.UserInfo, batch *dto.ProductImportBatch) *dto.ProductImportReport {
+	report := &dto.ProductImportReport{Errors: batch.Errors}
+	failedRows := map[int]bool{}
+	for _, e := range batch.Errors {
+		failedRows[e.Row] = true
+	}
+
+	for _, pi := range batch.Products {
+		product := pi.Product
+		var err error
+		failure := ErrFailedCreateProduct
+		if product.ID == 0 {
+			err = u.CreateProduct(ctx, userInfo.ID, &product)
+		} else {
+			err = u.importExistingProduct(ctx, userInfo, &product)
+			failure = ErrFailedUpdateProduct
+		}
+
+		if err != nil {
+			/* internal errors are not leaked into the report, only the ones the merchant can act on */
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				failure = ErrProductNotFound
+			case errors.Is(err, ErrWrongUserTryingToAccessMerchant):
+				failure = ErrWrongUserTryingToAccessMerchant
+			case errors.Is(err, ErrImportVariantGroupsChanged):
+				failure = ErrImportVariantGroupsChanged
+			}
+			report.Errors = append(report.Errors, dto.ProductImportRowError{Row: pi.Row, Message: failure.Error()})
+			failedRows[pi.Row] = true
+			continue
+		}
+		if pi.Product.ID == 0 {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+
+	report.Failed = uint64(len(failedRows))
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	return report
+}
+
+/* the csv only carries names, the ids of the stored groups, types, combinations and photos are matched by name */
+func (u *productUsecase) importExistingProduct(ctx context.Context, userInfo *dto.UserInfo, product *dto.ManageProduct) error {
+	existing, err := u.GetProductForEdit(ctx, userInfo, product.ID)
+	if err != nil {
+		return err
+	}
+
+	variants := &product.Variants
+	if (variants.Child == nil) != (existing.Variants.Child == nil) {
+		return ErrImportVariantGroupsChanged
+	}
+
+	variants.Parent.ID = existing.Variants.Parent.ID
+	matchVariantTypes(variants.Parent.Types, existing.Variants.Parent.Types)
+	if variants.Child != nil {
+		variants.Child.ID = existing.Variants.Child.ID
+		matchVariantTypes(variants.Child.Types, existing.Variants.Child.Types)
+	}
+
+	for i := range variants.Combinations {
+		c := &variants.Combinations[i]
+		c.ParentType = findVariantType(variants.Parent.Types, c.ParentType.Type)
+		if c.ChildType != nil {
+			childType := findVariantType(variants.Child.Types, c.ChildType.Type)
+			c.ChildType = &childType
+		}
+		for _, ec := range existing.Variants.Combinations {
+			if c.SameTypes(&ec) {
+				c.ID = ec.ID
+				break
+			}
+		}
+	}
+
+	if len(product.Photos) == 0 {
+		product.Photos = existing.Photos
+	}
+	for i := range product.Photos {
+		for _, ep := range existing.Photos {
+			if ep.URL == product.Photos[i].URL {
+				product.Photos[i].ID = ep.ID
+				break
+			}
+		}
+	}
+
+	return u.UpdateProduct(ctx, userInfo, product)
+}
+
+func matchVariantTypes(types []dto.ManageVariantType, existing []dto.ManageVariantType) {
+	for i := range types {
+		for _, et := range existing {
+			if et.Type == types[i].Type {
+				types[i].ID = et.ID
+				types[i].Image = et.Image
+				break
+			}
+		}
+	}
+}
+
+func findVariantType(types []dto.ManageVariantType, name string) dto.ManageVariantType {
+	for _, t := range types {
+		if t.Type == name {
+			return t
+		}
+	}
+	return dto.ManageVariantType{Type: name}
+}
+
+func (u *productUsecase) ExportProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.ManageProduct, error) {
+	productIDs, err := u.repo.ProductRepo.GetProductIDsByMerchantID(ctx, *userInfo.MerchantId)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/ExportProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+
+	products := []dto.ManageProduct{}
+	for _, id := range productIDs {
+		product, err := u.GetProductForEdit(ctx, userInfo, id)
+		if err != nil {
+			return nil, fmt.Errorf("productUsecase/ExportProducts: %w", err)
+		}
+		products = append(products, *product)
+	}
+	return products, nil
+}
+
+func (u *productUsecase) UpdateVariants(ctx context.Context, userInfo *dto.UserInfo, variants []dto.UpdateVariant) error {
+	if userInfo.MerchantId == nil {
+		return fmt.Errorf("productUsecase/UpdateVariants: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+
+	seen := map[uint64]bool{}
+	for _, v := range variants {
+		if seen[v.ID] || (v.Stock == nil && v.Price == nil) || (v.Price != nil && !v.Price.IsPositive()) {
+			return fmt.Errorf("productUsecase/UpdateVariants: %w", ErrInvalidVariantUpdate)
+		}
+		seen[v.ID] = true
+	}
+
+	if err := u.repo.VariantRepo.UpdateVariants(ctx, *userInfo.MerchantId, variants); err != nil {
+		return fmt.Errorf("productUsecase/UpdateVariants: %w", err)
+	}
+	return nil
+}
+
+func (u *productUsecase) GetStockHistory(ctx context.Context, userInfo *dto.UserInfo, req dto.StockMovementRequest) ([]model.StockMovement, string, error) {
+	if _, err := u.merchantProduct(ctx, userInfo, req.ProductId); err != nil {
+		return nil, "", fmt.Errorf("productUsecase/GetStockHistory: %w", err)
+	}
+
+	movements, nextCursor, err := u.repo.StockMovementRepo.GetStockMovements(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("productUsecase/GetStockHistory: %w", err)
+	}
+	return movements, nextCursor, nil
+}
+
+/* the product when it belongs to the merchant of the user */
+func (u *productUsecase) merchantProduct(ctx context.Context, userInfo *dto.UserInfo, productID uint64) (*model.Product, error) {
+	if userInfo.MerchantId == nil {
+		return nil, ErrWrongUserTryingToAccessMerchant
+	}
+	product, err := u.repo.ProductRepo.IsProductExist(ctx, productID)
+	if err != nil {
+		return nil, ErrProductNotFound
+	}
+	if product.MerchantId != *userInfo.MerchantId {
+		return nil, ErrUnauthorizedProduct
+	}
+	return &product, nil
+}
+
+func (u *productUsecase) GetDraftProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.DraftProductResponse, error) {
+	if userInfo.MerchantId == nil {
+		return nil, fmt.Errorf("productUsecase/GetDraftProducts: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	products, err := u.repo.ProductRepo.GetDraftProducts(ctx, *userInfo.MerchantId)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/GetDraftProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+
+	res := []dto.DraftProductResponse{}
+	for _, p := range products {
+		res = append(res, *dto.ToDraftProductResponse(&p))
+	}
+	return res, nil
+}
+
+func (u *productUsecase) DeleteProduct(ctx context.Context, userInfo *dto.UserInfo, productID uint64) error {
+	product, err := u.merchantProduct(ctx, userInfo, productID)
+	if err != nil {
+		return fmt.Errorf("productUsecase/DeleteProduct: %w", err)
+	}
+	if product.DeletedAt != nil {
+		return fmt.Errorf("productUsecase/DeleteProduct: %w", ErrProductNotFound)
+	}
+	if err := u.repo.ProductRepo.DeleteProduct(ctx, productID); err != nil {
+		return fmt.Errorf("productUsecase/DeleteProduct: %w", err)
+	}
+	_ = u.repo.SuggestionRepo.IndexProduct(ctx, productID)
+	return nil
+}
+
+func (u *productUsecase) RestoreProduct(ctx context.Context, userInfo *dto.UserInfo, productID uint64) error {
+	if _, err := u.merchantProduct(ctx, userInfo, productID); err != nil {
+		return fmt.Errorf("productUsecase/RestoreProduct: %w", err)
+	}
+	if err := u.repo.ProductRepo.RestoreProduct(ctx, productID); err != nil {
+		return fmt.Errorf("productUsecase/RestoreProduct: %w", err)
+	}
+	return nil
+}
+
+func (u *productUsecase) GetArchivedProducts(ctx context.Context, userInfo *dto.UserInfo) ([]dto.ArchivedProductResponse, error) {
+	if userInfo.MerchantId == nil {
+		return nil, fmt.Errorf("productUsecase/GetArchivedProducts: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	products, err := u.repo.ProductRepo.GetArchivedProducts(ctx, *userInfo.MerchantId)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/GetArchivedProducts: %s: %w", ErrFailedGettingProductsData, err)
+	}
+
+	res := []dto.ArchivedProductResponse{}
+	for _, p := range products {
+		res = append(res, *dto.ToArchivedProductResponse(&p))
+	}
+	return res, nil
+}
+
+func (u *productUsecase) SuggestProducts(ctx context.Context, prefix string, limit int64) (*dto.ProductSuggestionResponse, error) {
+	suggestion, err := u.repo.SuggestionRepo.Suggest(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("productUsecase/SuggestProducts: %w", err)
+	}
+	return dto.ToProductSuggestionResponse(suggestion), nil
+}
+
 func checkIfVariantTypeExist(varTypeID uint64, varTypeArr []dto.ManageVariantType) bool {
 	for _, vt := range varTypeArr {
 		if varTypeID == vt.ID {
@@ -16,6 +16,11 @@ import (
 type PromotionUsecase interface {
 	CreateMerchantPromotion(ctx context.Context, userInfo *dto.UserInfo, createPromotionDTO *dto.ManagePromotion) error
 	ListMerchantPromotionsByUsername(ctx context.Context, username string, listPromotionQueries dto.ListPromotionQueries) ([]dto.Promotion, *dto.PaginationInfo, error)
+	CreateGlobalPromotion(ctx context.Context, createPromotionDTO *dto.ManagePromotion) error
+	UpdateGlobalPromotion(ctx context.Context, updatePromotionDTO *dto.ManagePromotion) error
+	ListGlobalPromotions(ctx context.Context, listPromotionQueries dto.ListPromotionQueries) ([]dto.Promotion, *dto.PaginationInfo, error)
+	CreateFlashSale(ctx context.Context, userInfo *dto.UserInfo, createFlashSaleDTO *dto.ManageFlashSale) error
+	GetPromotionReport(ctx context.Context, userInfo *dto.UserInfo, promoID uint64) (*dto.PromotionReportResponse, error)
 }
 
 type promotionUsecase struct {
@@ -32,6 +37,14 @@ func (u *promotionUsecase) CreateMerchantPromotion(ctx context.Context, userInfo
 	if createPromotionDTO.Promotion.PromotionType.String() == shared.Discount.String() && createPromotionDTO.Promotion.Amount.GreaterThan(decimal.NewFromInt(1)) {
 		return fmt.Errorf("promotionUsecase/CreateMerchantPromotion: %w", ErrInvalidAmountForDiscountTypePromo)
 	}
+	if createPromotionDTO.Promotion.PromotionType.String() == shared.FreeShipping.String() && createPromotionDTO.Promotion.Amount.GreaterThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("promotionUsecase/CreateMerchantPromotion: %w", ErrInvalidAmountForShippingTypePromo)
+	}
+	if createPromotionDTO.Promotion.PromotionType.IsBundle() {
+		if err := validateBundlePromotion(createPromotionDTO); err != nil {
+			return fmt.Errorf("promotionUsecase/CreateMerchantPromotion: %w", err)
+		}
+	}
 	if createPromotionDTO.Promotion.PromotionScope.String() == shared.ProductScope.String() {
 		if createPromotionDTO.Products == nil || len(createPromotionDTO.Products) == 0 {
 			return fmt.Errorf("promotionUsecase/CreateMerchantPromotion: %w", ErrMustSpecifyProduct)
@@ -109,3 +122,180 @@ func (u *promotionUsecase) ListMerchantPromotionsByUsername(ctx context.Context,
 
 	return res, &pageInfo, nil
 }
+
+func validateBundlePromotion(promotionDTO *dto.ManagePromotion) error {
+	promotion := promotionDTO.Promotion
+	if promotion.PromotionScope.String() != shared.ProductScope.String() {
+		return ErrInvalidBundlePromotion
+	}
+	switch promotion.PromotionType.String() {
+	case shared.BuyXGetY.String():
+		if promotion.BuyQuantity == 0 || promotion.GetQuantity == 0 {
+			return ErrInvalidBundlePromotion
+		}
+	case shared.Bundle.String():
+		/* amount is the fixed price of one unit of every product in the bundle */
+		if len(promotionDTO.Products) < 2 || !promotion.Amount.IsPositive() {
+			return ErrInvalidBundlePromotion
+		}
+	}
+	return nil
+}
+
+func validateGlobalPromotion(promotionDTO *dto.ManagePromotion) error {
+	if len(promotionDTO.Products) > 0 {
+		return ErrGlobalPromotionWithProducts
+	}
+	if promotionDTO.Promotion.PromotionType.IsBundle() {
+		return ErrInvalidBundlePromotion
+	}
+	if promotionDTO.Promotion.EndDate.Before(promotionDTO.Promotion.StartDate) {
+		return ErrInvalidPromotionPeriod
+	}
+	if promotionDTO.Promotion.PromotionType.String() == shared.Discount.String() {
+		if promotionDTO.Promotion.Amount.GreaterThan(decimal.NewFromInt(1)) {
+			return ErrInvalidAmountForDiscountTypePromo
+		}
+		/* checkout caps global discounts by max amount, so it is mandatory here */
+		if promotionDTO.Promotion.MaxAmount == nil || !promotionDTO.Promotion.MaxAmount.IsPositive() {
+			return ErrInvalidMaxAmount
+		}
+	}
+	if promotionDTO.Promotion.PromotionType.String() == shared.FreeShipping.String() && promotionDTO.Promotion.Amount.GreaterThan(decimal.NewFromInt(1)) {
+		return ErrInvalidAmountForShippingTypePromo
+	}
+	return nil
+}
+
+func (u *promotionUsecase) CreateGlobalPromotion(ctx context.Context, createPromotionDTO *dto.ManagePromotion) error {
+	createPromotionDTO.Promotion.PromotionScope = shared.GlobalScope
+	if err := validateGlobalPromotion(createPromotionDTO); err != nil {
+		return fmt.Errorf("promotionUsecase/CreateGlobalPromotion: %w", err)
+	}
+
+	err := u.repo.PromotionRepo.CreateGlobalPromotion(ctx, createPromotionDTO)
+	if err != nil {
+		return fmt.Errorf("promotionUsecase/CreateGlobalPromotion: %s: %w", ErrFailedToCreatePromotion, err)
+	}
+	return nil
+}
+
+func (u *promotionUsecase) UpdateGlobalPromotion(ctx context.Context, updatePromotionDTO *dto.ManagePromotion) error {
+	existing, err := u.repo.PromotionRepo.GetPromotionByID(ctx, nil, updatePromotionDTO.Promotion.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPromotionNotFound) {
+			return fmt.Errorf("promotionUsecase/UpdateGlobalPromotion: %w", ErrPromotionNotFound)
+		}
+		return fmt.Errorf("promotionUsecase/UpdateGlobalPromotion: %w", err)
+	}
+	if existing.PromotionScope != shared.GlobalScope.String() {
+		return fmt.Errorf("promotionUsecase/UpdateGlobalPromotion: %w", ErrNotGlobalPromotion)
+	}
+
+	updatePromotionDTO.Promotion.PromotionScope = shared.GlobalScope
+	if err := validateGlobalPromotion(updatePromotionDTO); err != nil {
+		return fmt.Errorf("promotionUsecase/UpdateGlobalPromotion: %w", err)
+	}
+
+	if _, err := u.repo.PromotionRepo.UpdatePromotion(ctx, nil, updatePromotionDTO.Promotion.ToModel()); err != nil {
+		return fmt.Errorf("promotionUsecase/UpdateGlobalPromotion: %s: %w", ErrFailedToUpdatePromotion, err)
+	}
+	return nil
+}
+
+func (u *promotionUsecase) ListGlobalPromotions(ctx context.Context, listPromotionQueries dto.ListPromotionQueries) ([]dto.Promotion, *dto.PaginationInfo, error) {
+	promotionsList, totalItems, err := u.repo.PromotionRepo.ListGlobalPromotions(ctx, nil, listPromotionQueries)
+	if err != nil {
+		return nil, nil, fmt.Errorf("promotionUsecase/ListGlobalPromotions: %w", err)
+	}
+
+	res := []dto.Promotion{}
+	for _, promotion := range promotionsList {
+		res = append(res, *dto.PromotionToDTO(promotion))
+	}
+
+	pageInfo := dto.PaginationInfo{
+		TotalItems:  int64(totalItems),
+		TotalPages:  (int64(totalItems) + int64(listPromotionQueries.Limit) - 1) / int64(listPromotionQueries.Limit),
+		CurrentPage: int64(listPromotionQueries.Page),
+	}
+
+	return res, &pageInfo, nil
+}
+
+func (u *promotionUsecase) CreateFlashSale(ctx context.Context, userInfo *dto.UserInfo, createFlashSaleDTO *dto.ManageFlashSale) error {
+	if !createFlashSaleDTO.EndDate.After(createFlashSaleDTO.StartDate) {
+		return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrInvalidPromotionPeriod)
+	}
+
+	seen := map[uint64]bool{}
+	for _, p := range createFlashSaleDTO.Products {
+		if seen[p.VariantCombinationProductID] {
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrDuplicateFlashSaleVariant)
+		}
+		seen[p.VariantCombinationProductID] = true
+	}
+
+	for i, p := range createFlashSaleDTO.Products {
+		product, err := u.repo.ProductRepo.GetProductByVariantCombinationProductId(ctx, p.VariantCombinationProductID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrVariantNotFound)
+			}
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", err)
+		}
+		if product.MerchantId != *userInfo.MerchantId {
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrWrongUserTryingToAccessMerchant)
+		}
+
+		variant, ok := u.repo.VariantRepo.IsVariantCombinationProductIsExists(ctx, p.VariantCombinationProductID)
+		if !ok {
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrVariantNotFound)
+		}
+		if !p.SalePrice.IsPositive() || !p.SalePrice.LessThan(variant.Price) {
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrInvalidFlashSalePrice)
+		}
+		if p.SaleStock > variant.Stock {
+			return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", ErrInvalidFlashSaleStock)
+		}
+		createFlashSaleDTO.Products[i].ProductID = product.ID
+	}
+
+	if err := u.repo.FlashSaleRepo.CreateFlashSale(ctx, createFlashSaleDTO.ToModel(*userInfo.MerchantId)); err != nil {
+		return fmt.Errorf("promotionUsecase/CreateFlashSale: %w", err)
+	}
+	return nil
+}
+
+func (u *promotionUsecase) GetPromotionReport(ctx context.Context, userInfo *dto.UserInfo, promoID uint64) (*dto.PromotionReportResponse, error) {
+	promotion, err := u.repo.PromotionRepo.GetPromotionByID(ctx, nil, promoID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPromotionNotFound) {
+			return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", ErrPromotionNotFound)
+		}
+		return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", err)
+	}
+
+	merchantID, err := u.repo.PromotionRepo.GetPromotionMerchantID(ctx, nil, promoID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPromotionNotFound) {
+			return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", ErrPromotionNotFound)
+		}
+		return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", err)
+	}
+
+	/* global promotions belong to the platform, the rest to the owning merchant */
+	if merchantID == nil {
+		if !userInfo.IsAdmin {
+			return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", ErrWrongUserTryingToAccessMerchant)
+		}
+	} else if userInfo.MerchantId == nil || *userInfo.MerchantId != *merchantID {
+		return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+
+	total, daily, err := u.repo.PromotionRepo.GetPromotionReport(ctx, promotion, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("promotionUsecase/GetPromotionReport: %w", err)
+	}
+	return dto.ToPromotionReportResponse(*promotion, *total, daily), nil
+}
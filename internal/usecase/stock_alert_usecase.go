@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"digital-test-vm/be/internal/dto"
+	"digital-test-vm/be/internal/model"
+	repo "digital-test-vm/be/internal/repository"
+	"digital-test-vm/be/internal/shared"
+	"fmt"
+	"strings"
+)
+
+type StockAlertUsecase interface {
+	UpdateStockAlert(ctx context.Context, userInfo *dto.UserInfo, productId uint64, req dto.UpdateStockAlertRequest) error
+	CheckStock(ctx context.Context) error
+}
+
+type stockAlertUsecase struct {
+	repo *repo.Repo
+}
+
+func NewStockAlertUsecase(repo *repo.Repo) StockAlertUsecase {
+	return &stockAlertUsecase{repo: repo}
+}
+
+func (u *stockAlertUsecase) UpdateStockAlert(ctx context.Context, userInfo *dto.UserInfo, productId uint64, req dto.UpdateStockAlertRequest) error {
+	if userInfo.MerchantId == nil {
+		return fmt.Errorf("stockAlertUsecase/UpdateStockAlert: %w", ErrWrongUserTryingToAccessMerchant)
+	}
+	product, err := u.repo.ProductRepo.IsProductExist(ctx, productId)
+	if err != nil {
+		return fmt.Errorf("stockAlertUsecase/UpdateStockAlert: %w", ErrProductNotFound)
+	}
+	if product.MerchantId != *userInfo.MerchantId {
+		return fmt.Errorf("stockAlertUsecase/UpdateStockAlert: %w", ErrUnauthorizedProduct)
+	}
+	if err := u.repo.StockAlertRepo.UpdateStockAlert(ctx, productId, *req.LowStockThreshold, *req.AutoDeactivate); err != nil {
+		return fmt.Errorf("stockAlertUsecase/UpdateStockAlert: %w", err)
+	}
+	return nil
+}
+
+/*
+reports variants that reached the low stock threshold of their product once, hides products that sold out when
+the merchant asked for it and shows them again after a restock. a product that fails is picked up on the next run
+*/
+func (u *stockAlertUsecase) CheckStock(ctx context.Context) error {
+	if err := u.repo.StockAlertRepo.ClearRecoveredLowStock(ctx); err != nil {
+		return fmt.Errorf("stockAlertUsecase/CheckStock: %w", err)
+	}
+	if err := u.notifyLowStock(ctx); err != nil {
+		return fmt.Errorf("stockAlertUsecase/CheckStock: %w", err)
+	}
+
+	outOfStock, err := u.repo.StockAlertRepo.GetOutOfStockProducts(ctx)
+	if err != nil {
+		return fmt.Errorf("stockAlertUsecase/CheckStock: %w", err)
+	}
+	for _, p := range outOfStock {
+		if err := u.repo.ProductRepo.DeactivateProduct(ctx, p.ProductId); err != nil {
+			continue
+		}
+		if err := u.repo.StockAlertRepo.SetAutoDeactivated(ctx, p.ProductId, true); err != nil {
+			continue
+		}
+		_ = u.repo.SuggestionRepo.IndexProduct(ctx, p.ProductId)
+		_ = notify(ctx, u.repo, p.Email, stockNotification(p.UserId, p.ProductId, shared.NotificationProductDeactivated,
+			"Product hidden: "+p.Title,
+			fmt.Sprintf("%s is out of stock and has been deactivated. It will be shown again once it is restocked.", p.Title)))
+	}
+
+	restocked, err := u.repo.StockAlertRepo.GetRestockedProducts(ctx)
+	if err != nil {
+		return fmt.Errorf("stockAlertUsecase/CheckStock: %w", err)
+	}
+	for _, p := range restocked {
+		if err := u.repo.ProductRepo.ActivateProduct(ctx, p.ProductId); err != nil {
+			continue
+		}
+		if err := u.repo.StockAlertRepo.SetAutoDeactivated(ctx, p.ProductId, false); err != nil {
+			continue
+		}
+		_ = u.repo.SuggestionRepo.IndexProduct(ctx, p.ProductId)
+		_ = notify(ctx, u.repo, p.Email, stockNotification(p.UserId, p.ProductId, shared.NotificationProductReactivated,
+			"Product active again: "+p.Title,
+			fmt.Sprintf("%s has been restocked and is active again.", p.Title)))
+	}
+	return nil
+}
+
+/* one notification per product listing every variant at or below the threshold */
+func (u *stockAlertUsecase) notifyLowStock(ctx context.Context) error {
+	variants, err := u.repo.StockAlertRepo.GetLowStockVariants(ctx)
+	if err != nil {
+		return err
+	}
+
+	notified := []uint64{}
+	for start := 0; start < len(variants); {
+		end := start
+		lines := []string{}
+		for end < len(variants) && variants[end].ProductId == variants[start].ProductId {
+			lines = append(lines, fmt.Sprintf("%s: %d left", variants[end].VariantName, variants[end].Stock))
+			end++
+		}
+
+		v := variants[start]
+		start = end
+		message := fmt.Sprintf("Stock of %s is at or below %d. %s.", v.Title, v.LowStockThreshold, strings.Join(lines, ", "))
+		if err := notify(ctx, u.repo, v.Email, stockNotification(v.UserId, v.ProductId, shared.NotificationLowStock, "Low stock: "+v.Title, message)); err != nil {
+			continue
+		}
+		notified = append(notified, v.ProductId)
+	}
+	return u.repo.StockAlertRepo.MarkLowStockNotified(ctx, notified)
+}
+
+func stockNotification(userId uint64, productId uint64, kind shared.NotificationType, title string, message string) model.Notification {
+	return model.Notification{
+		UserId:    userId,
+		Type:      kind.String(),
+		Title:     title,
+		Message:   message,
+		ProductId: &productId,
+	}
+}
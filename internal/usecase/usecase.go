@@ -15,6 +15,10 @@ type Usecase struct {
 	OrderUsecase           OrderUsecase
 	CheckoutUsecase        CheckoutUsecase
 	PromotionUsecase       PromotionUsecase
+	MediaUsecase           MediaUsecase
+	NotificationUsecase    NotificationUsecase
+	StockAlertUsecase      StockAlertUsecase
+	ProductQuestionUsecase ProductQuestionUsecase
 	Cron                   Cron
 }
 
@@ -32,6 +36,10 @@ func NewUsecase(repo *repo.Repo) *Usecase {
 		OrderUsecase:           NewOrderUsecase(repo),
 		CheckoutUsecase:        NewCheckoutUsecase(repo),
 		PromotionUsecase:       NewPromotionUsecase(repo),
-		Cron:                   *New(repo.OrderRepo),
+		MediaUsecase:           NewMediaUsecase(repo),
+		NotificationUsecase:    NewNotificationUsecase(repo),
+		StockAlertUsecase:      NewStockAlertUsecase(repo),
+		ProductQuestionUsecase: NewProductQuestionUsecase(repo),
+		Cron:                   *New(repo),
 	}
 }
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+		size   int
+		width  int
+		height int
+	}{
+		{name: "landscape fits the width", bounds: image.Rect(0, 0, 400, 200), size: 100, width: 100, height: 50},
+		{name: "portrait fits the height", bounds: image.Rect(0, 0, 200, 400), size: 100, width: 50, height: 100},
+		{name: "square", bounds: image.Rect(0, 0, 300, 300), size: 100, width: 100, height: 100},
+		{name: "small image is kept", bounds: image.Rect(0, 0, 80, 60), size: 100, width: 80, height: 60},
+		{name: "thin image keeps one pixel", bounds: image.Rect(0, 0, 1000, 2), size: 100, width: 100, height: 1},
+		{name: "bounds not at the origin", bounds: image.Rect(50, 50, 250, 150), size: 100, width: 100, height: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewNRGBA(tt.bounds)
+			got := Thumbnail(src, tt.size).Bounds()
+
+			if got.Dx() != tt.width || got.Dy() != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	/* left half black, right half white, each target pixel covers one half */
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.NRGBA{A: 255}
+			if x >= 2 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+
+	dst := Thumbnail(src, 2)
+
+	tests := []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{x: 0, y: 0, want: color.NRGBA{A: 255}},
+		{x: 1, y: 0, want: color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+	for _, tt := range tests {
+		if got := color.NRGBAModel.Convert(dst.At(tt.x, tt.y)).(color.NRGBA); got != tt.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
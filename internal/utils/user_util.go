@@ -39,3 +39,16 @@ func GetUserFromContext(c *gin.Context) (*dto.UserInfo, error) {
 
 	return user, nil
 }
+
+/* viewer of a route that does not require login, nil when the request is anonymous */
+func GetViewerIDFromContext(c *gin.Context) *uint64 {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return nil
+	}
+	jwtClaims, ok := claims.(*auth.JWTClaim)
+	if !ok {
+		return nil
+	}
+	return &jwtClaims.User.ID
+}